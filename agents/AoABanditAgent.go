@@ -0,0 +1,195 @@
+package agents
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	common "github.com/ADimoska/SOMASExtended/common"
+
+	"github.com/MattSScott/basePlatformSOMAS/v2/pkg/agent"
+	"github.com/google/uuid"
+)
+
+// AoAOutcome is how an iteration went for an agent under an AoA
+type AoAOutcome struct {
+	AoAID    int
+	Survived bool
+	// score at the end of the iteration (0 if the agent died)
+	Score int
+	// times the agent was caught by an audit
+	Punished int
+}
+
+// scale of the score reward, a score of scoreScale is worth half of the score reward
+const scoreScale = 50.0
+
+// share of the reward lost each time the agent is punished
+const punishmentCost = 0.1
+
+// Reward of the outcome between 0 and 1: surviving is worth half, the score up to the
+// other half, and each punishment takes punishmentCost off
+func (outcome AoAOutcome) Reward() float64 {
+	reward := 0.5 * float64(max(0, outcome.Score)) / (float64(max(0, outcome.Score)) + scoreScale)
+	if outcome.Survived {
+		reward += 0.5
+	}
+	reward -= punishmentCost * float64(outcome.Punished)
+	return min(1, max(0, reward))
+}
+
+// statistics of one AoA (one arm of the bandit)
+type aoaArm struct {
+	pulls  float64
+	reward float64
+}
+
+// AoABanditAgent learns which AoA to vote for over the iterations. It remembers how each
+// iteration went under the AoA its team adopted, and ranks the AoAs by their UCB1 index
+// before every AoA vote, so AoAs that worked well (or were rarely tried) come first.
+type AoABanditAgent struct {
+	*ExtendedAgent
+	// weight of exploration in the UCB1 index
+	Exploration float64
+
+	arms map[int]*aoaArm
+	// the outcomes the agent learnt from, in order
+	Outcomes []AoAOutcome
+
+	// turns spent under each AoA this iteration, and the order they were first seen in
+	turnsUnder map[int]int
+	aoaOrder   []int
+	punished   int
+}
+
+// default weight of exploration, the one UCB1 was designed with
+var DefaultExploration = math.Sqrt2
+
+func CreateAoABanditAgent(funcs agent.IExposedServerFunctions[common.IExtendedAgent], agentConfig AgentConfig) *AoABanditAgent {
+	bandit := &AoABanditAgent{
+		ExtendedAgent: GetBaseAgents(funcs, agentConfig),
+		Exploration:   DefaultExploration,
+		arms:          make(map[int]*aoaArm),
+		turnsUnder:    make(map[int]int),
+	}
+	bandit.SetAoARanking(bandit.RankAoAs())
+	return bandit
+}
+
+// Remember the AoA the agent lives under this turn
+func (ba *AoABanditAgent) OnTurnStart(iteration int, turn int) {
+	aoaID := ba.Server.GetTeamAoAID()
+	if aoaID == 0 {
+		return
+	}
+	if ba.turnsUnder[aoaID] == 0 {
+		ba.aoaOrder = append(ba.aoaOrder, aoaID)
+	}
+	ba.turnsUnder[aoaID]++
+}
+
+func (ba *AoABanditAgent) SetAgentContributionAuditResult(agentID uuid.UUID, result bool) {
+	ba.ExtendedAgent.SetAgentContributionAuditResult(agentID, result)
+	if agentID == ba.GetID() && result {
+		ba.punished++
+	}
+}
+
+func (ba *AoABanditAgent) SetAgentWithdrawalAuditResult(agentID uuid.UUID, result bool) {
+	ba.ExtendedAgent.SetAgentWithdrawalAuditResult(agentID, result)
+	if agentID == ba.GetID() && result {
+		ba.punished++
+	}
+}
+
+// Credit the outcome of the iteration to the AoA the agent spent the most turns under
+func (ba *AoABanditAgent) OnIterationEnd(iteration int) {
+	aoaID := 0
+	for _, id := range ba.aoaOrder {
+		if ba.turnsUnder[id] > ba.turnsUnder[aoaID] {
+			aoaID = id
+		}
+	}
+	if aoaID != 0 {
+		outcome := AoAOutcome{
+			AoAID:    aoaID,
+			Survived: !ba.Server.IsAgentDead(ba.GetID()),
+			Punished: ba.punished,
+		}
+		if outcome.Survived {
+			outcome.Score = ba.GetTrueScore()
+		}
+		ba.learn(outcome)
+		if ba.VerboseLevel > 6 {
+			log.Printf("Agent %s learnt from iteration %d under AoA %d: reward %.2f\n", ba.GetID(), iteration, aoaID, outcome.Reward())
+		}
+	}
+
+	ba.turnsUnder = make(map[int]int)
+	ba.aoaOrder = nil
+	ba.punished = 0
+}
+
+func (ba *AoABanditAgent) learn(outcome AoAOutcome) {
+	arm, ok := ba.arms[outcome.AoAID]
+	if !ok {
+		arm = &aoaArm{}
+		ba.arms[outcome.AoAID] = arm
+	}
+	arm.pulls++
+	arm.reward += outcome.Reward()
+	ba.Outcomes = append(ba.Outcomes, outcome)
+}
+
+// Forget past outcomes as far as the memory policy says, then rank the AoAs for the
+// coming vote
+func (ba *AoABanditAgent) ResetForIteration(policy common.MemoryPolicy) {
+	ba.ExtendedAgent.ResetForIteration(policy)
+	for aoaID, arm := range ba.arms {
+		arm.pulls = policy.Retain(arm.pulls, 0)
+		arm.reward = policy.Retain(arm.reward, 0)
+		if arm.pulls == 0 {
+			delete(ba.arms, aoaID)
+		}
+	}
+	kept := policy.RecentCount(len(ba.Outcomes))
+	ba.Outcomes = append([]AoAOutcome{}, ba.Outcomes[len(ba.Outcomes)-kept:]...)
+	ba.SetAoARanking(ba.RankAoAs())
+}
+
+// RankAoAs orders the registered AoAs by their UCB1 index, highest first. AoAs the agent
+// has never seen come first, in a random order.
+func (ba *AoABanditAgent) RankAoAs() []int {
+	aoaIDs := common.RegisteredAoAIDs()
+	// shuffle first so ties are broken at random
+	ba.rng.Shuffle(len(aoaIDs), func(i, j int) {
+		aoaIDs[i], aoaIDs[j] = aoaIDs[j], aoaIDs[i]
+	})
+
+	totalPulls := 0.0
+	for _, arm := range ba.arms {
+		totalPulls += arm.pulls
+	}
+	index := make(map[int]float64, len(aoaIDs))
+	for _, aoaID := range aoaIDs {
+		arm, ok := ba.arms[aoaID]
+		if !ok {
+			index[aoaID] = math.Inf(1)
+			continue
+		}
+		index[aoaID] = arm.reward/arm.pulls + ba.Exploration*math.Sqrt(math.Log(max(1, totalPulls))/arm.pulls)
+	}
+	sort.SliceStable(aoaIDs, func(i, j int) bool {
+		return index[aoaIDs[i]] > index[aoaIDs[j]]
+	})
+	return aoaIDs
+}
+
+// Mean reward of each AoA the agent has tried
+func (ba *AoABanditAgent) MeanRewards() map[int]float64 {
+	means := make(map[int]float64, len(ba.arms))
+	for aoaID, arm := range ba.arms {
+		means[aoaID] = arm.reward / arm.pulls
+	}
+	return means
+}
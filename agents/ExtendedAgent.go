@@ -1,9 +1,9 @@
 package agents
 
 import (
+	"fmt"
 	"log"
 	"math/rand"
-	"time"
 
 	"github.com/google/uuid"
 
@@ -19,20 +19,29 @@ import (
 
 type ExtendedAgent struct {
 	*agent.BaseAgent[common.IExtendedAgent]
-	Server common.IServer
+	// given by the server (see common.IServer.NewAgentID), replaces the base agent's ID
+	id uuid.UUID
+	// what the agent may ask the server, see common.IAgentServer
+	Server common.IAgentServer
 	Score  int
 	TeamID uuid.UUID
 	Name   int
 
-	// private
+	// last roll of the agent's previous turn
 	LastScore int
 
 	// debug
 	VerboseLevel int
 
+	// random stream derived from the agent's ID, so seeded runs are reproducible
+	rng *rand.Rand
+
 	// AoA vote
 	AoARanking []int
 
+	// last thing the server told the agent about the threshold, nil if nothing
+	thresholdInfo *common.ThresholdNotificationMessage
+
 	LastTeamID uuid.UUID // Tracks the last team the agent was part of
 
 	// for recording purpose
@@ -49,9 +58,28 @@ type AgentConfig struct {
 }
 
 func GetBaseAgents(funcs agent.IExposedServerFunctions[common.IExtendedAgent], configParam AgentConfig) *ExtendedAgent {
-	aoaRanking := []int{1, 2, 3, 4, 5, 6}
-
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// every registered AoA is a candidate
+	aoaRanking := common.RegisteredAoAIDs()
+
+	// the agent only sees the server through its view, a server that already is a view
+	// (e.g. a test double) is used as it is
+	var view common.IAgentServer
+	id := uuid.Nil
+	switch server := funcs.(type) {
+	case common.IServer:
+		view = server.NewAgentView()
+		id = server.NewAgentID()
+	case common.IAgentServer:
+		view = server
+	default:
+		panic(fmt.Sprintf("GetBaseAgents: %T is neither a common.IServer nor a common.IAgentServer", funcs))
+	}
+	baseAgent := agent.CreateBaseAgent[common.IExtendedAgent](view)
+	if id == uuid.Nil {
+		id = baseAgent.GetID()
+	}
+	view.SetOwner(id)
+	rng := common.NewRandFromID(id)
 
 	// Shuffle the slice to create a random order.
 	rng.Shuffle(len(aoaRanking), func(i, j int) {
@@ -59,16 +87,32 @@ func GetBaseAgents(funcs agent.IExposedServerFunctions[common.IExtendedAgent], c
 	})
 
 	return &ExtendedAgent{
-		BaseAgent:    agent.CreateBaseAgent(funcs),
-		Server:       funcs.(common.IServer), // Type assert the server functions to IServer interface
+		BaseAgent:    baseAgent,
+		id:           id,
+		Server:       view,
 		Score:        configParam.InitScore,
 		VerboseLevel: configParam.VerboseLevel,
 		AoARanking:   aoaRanking,
+		rng:          rng,
 	}
 }
 
 // ----------------------- Interface implementation -----------------------
 
+// The base agent draws its ID from the uuid package's global source, so the agent uses the
+// one the server gave it instead, also as the sender of its messages
+func (mi *ExtendedAgent) GetID() uuid.UUID {
+	return mi.id
+}
+
+func (mi *ExtendedAgent) CreateBaseMessage() message.BaseMessage {
+	return message.BaseMessage{Sender: mi.id}
+}
+
+func (mi *ExtendedAgent) SignalMessagingComplete() {
+	go mi.AgentStoppedTalking(mi.id)
+}
+
 // Get the agent's current team ID
 func (mi *ExtendedAgent) GetTeamID() uuid.UUID {
 	return mi.TeamID
@@ -99,53 +143,46 @@ func (mi *ExtendedAgent) SetName(name int) {
 	mi.Name = name
 }
 
-// custom function: ask for rolling the dice
-func (mi *ExtendedAgent) StartRollingDice(instance common.IExtendedAgent) {
-	if mi.VerboseLevel > 10 {
-		log.Printf("%s is rolling the Dice\n", mi.GetID())
-	}
-	if mi.VerboseLevel > 9 {
-		log.Println("---------------------")
-	}
-	// TODO: implement the logic in environment, do a random of 3d6 now with 50% chance to stick
-	mi.LastScore = -1
-	rounds := 1
-	turnScore := 0
-
-	willStick := false
-
-	// loop until not stick
-	for !willStick {
-		// debug add score directly
-		currentScore := Roll3Dice()
-
-		// check if currentScore is higher than lastScore
-		if currentScore > mi.LastScore {
-			turnScore += currentScore
-			mi.LastScore = currentScore
-			willStick = instance.StickOrAgain(turnScore, currentScore)
-			if willStick {
-				mi.DecideStick() //used just for debugging
-				break
-			}
-			mi.DecideRollAgain() //used just for debugging
-		} else {
-			// burst, lose all turn score
-			if mi.VerboseLevel > 4 {
-				log.Printf("%s **BURSTED!** round: %v, current score: %v\n", mi.GetID(), rounds, currentScore)
-			}
-			turnScore = 0
-			break
-		}
-
-		rounds++
+// The server rolls the dice for the agent, asking StickOrAgain after every roll, and
+// reports the outcome of the turn here. The score has already been updated.
+func (mi *ExtendedAgent) HandleRollResult(roll gameRecorder.RollRecord) {
+	if len(roll.Rolls) > 0 {
+		mi.LastScore = roll.Rolls[len(roll.Rolls)-1]
+	}
+	if roll.Bust && mi.VerboseLevel > 4 {
+		log.Printf("%s **BURSTED!** round: %v, current score: %v\n", mi.GetID(), len(roll.Rolls), mi.LastScore)
+	}
+	if mi.VerboseLevel > 4 {
+		log.Printf("%s's turn score: %v, total score: %v\n", mi.GetID(), roll.TurnScore, mi.Score)
 	}
+}
 
-	// add turn score to total score
-	mi.Score += turnScore
+// Lifecycle notifications from the server. They do nothing by default, agents override
+// the ones they need instead of polling the server.
 
-	if mi.VerboseLevel > 4 {
-		log.Printf("%s's turn score: %v, total score: %v\n", mi.GetID(), turnScore, mi.Score)
+func (mi *ExtendedAgent) OnTurnStart(iteration int, turn int) {}
+
+func (mi *ExtendedAgent) OnTurnEnd(results common.TurnResults) {}
+
+func (mi *ExtendedAgent) OnThresholdApplied(result common.ThresholdResult) {}
+
+// Called for each living member of a team when one of its members dies, with the
+// score the teammate had when it died
+func (mi *ExtendedAgent) OnTeammateDied(agentID uuid.UUID, score int) {}
+
+// Called when the agent is revived at the start of an iteration
+func (mi *ExtendedAgent) OnRevived() {}
+
+// Called for every agent, dead or alive, at the end of an iteration
+func (mi *ExtendedAgent) OnIterationEnd(iteration int) {}
+
+// The server resets the score and team, the agent forgets what it learnt last iteration
+// as far as the policy says. Agents that remember more override this and call it.
+func (mi *ExtendedAgent) ResetForIteration(policy common.MemoryPolicy) {
+	if !policy.Keeps() {
+		mi.thresholdInfo = nil
+		mi.LastTeamID = uuid.Nil
+		mi.LastScore = 0
 	}
 }
 
@@ -154,20 +191,20 @@ func (mi *ExtendedAgent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
 	// if mi.verboseLevel > 8 {
 	// 	log.Printf("%s is deciding to stick or again\n", mi.GetID())
 	// }
-	return rand.Intn(2) == 0
+	return mi.rng.Intn(2) == 0
 }
 
 // decide to stick
 func (mi *ExtendedAgent) DecideStick() {
 	if mi.VerboseLevel > 6 {
-		log.Printf("%s decides to [STICK], last score: %v\n", mi.GetID(), mi.LastScore)
+		log.Printf("%s decides to [STICK]\n", mi.GetID())
 	}
 }
 
 // decide to roll again
 func (mi *ExtendedAgent) DecideRollAgain() {
 	if mi.VerboseLevel > 6 {
-		log.Printf("%s decides to ROLL AGAIN, last score: %v\n", mi.GetID(), mi.LastScore)
+		log.Printf("%s decides to ROLL AGAIN\n", mi.GetID())
 	}
 }
 
@@ -176,12 +213,12 @@ func (mi *ExtendedAgent) DecideRollAgain() {
 // This function MUST return the same value when called multiple times in the same turn
 func (mi *ExtendedAgent) GetActualContribution(instance common.IExtendedAgent) int {
 	if mi.HasTeam() {
-		contribution := mi.Server.GetTeam(mi.GetID()).TeamAoA.GetExpectedContribution(mi.GetID(), mi.GetTrueScore())
+		contribution := mi.Server.GetTeamAoA().GetExpectedContribution(mi.GetID(), mi.GetTrueScore())
 		if mi.GetTrueScore() < contribution {
 			contribution = mi.GetTrueScore() // give all score if less than expected
 		}
 		if mi.VerboseLevel > 6 {
-			log.Printf("%s is contributing %d to the common pool and thinks the common pool size is %d\n", mi.GetID(), contribution, mi.Server.GetTeam(mi.GetID()).GetCommonPool())
+			log.Printf("%s is contributing %d to the common pool and thinks the common pool size is %d\n", mi.GetID(), contribution, mi.Server.GetTeamCommonPool())
 		}
 		return contribution
 	} else {
@@ -212,8 +249,8 @@ func (mi *ExtendedAgent) GetActualWithdrawal(instance common.IExtendedAgent) int
 	if !mi.HasTeam() {
 		return 0
 	}
-	commonPool := mi.Server.GetTeam(mi.GetID()).GetCommonPool()
-	withdrawal := mi.Server.GetTeam(mi.GetID()).TeamAoA.GetExpectedWithdrawal(mi.GetID(), mi.GetTrueScore(), commonPool)
+	commonPool := mi.Server.GetTeamCommonPool()
+	withdrawal := mi.Server.GetTeamAoA().GetExpectedWithdrawal(mi.GetID(), mi.GetTrueScore(), commonPool)
 	if commonPool < withdrawal {
 		withdrawal = commonPool
 	}
@@ -229,7 +266,7 @@ func (mi *ExtendedAgent) GetStatedWithdrawal(instance common.IExtendedAgent) int
 		return 0
 	}
 	// Currently, assume stated withdrawal matches actual withdrawal
-	return instance.GetActualContribution(instance)
+	return instance.GetActualWithdrawal(instance)
 }
 
 func (mi *ExtendedAgent) GetName() int {
@@ -242,22 +279,8 @@ func (mi *ExtendedAgent) GetName() int {
  * are currently being punished as a result of an audit.
  */
 func (mi *ExtendedAgent) GetLeaveOpinion(agentID uuid.UUID) bool {
-	// Recursion block
-	if mi.GetID() == agentID {
-		return false
-	}
-	// Get the underlying agent's opinion
-	return mi.Server.AccessAgentByID(agentID).GetLeaveOpinion(mi.GetID())
-}
-
-/*
-Provide agentId for memory, current accumulated score
-(to see if above or below predicted threshold for common pool contribution)
-And previous roll in case relevant
-*/
-func (mi *ExtendedAgent) StickOrAgainFor(agentId uuid.UUID, accumulatedScore int, prevRoll int) int {
-	// random chance, to simulate what is already implemented
-	return rand.Intn(2)
+	// The base agent never wants to leave
+	return false
 }
 
 // dev function
@@ -302,7 +325,7 @@ func (mi *ExtendedAgent) HandleTeamFormationMessage(msg *common.TeamFormationMes
 	// Handle team creation/joining based on sender's team status
 	sender := msg.GetSender()
 	if mi.Server.CheckAgentAlreadyInTeam(sender) {
-		existingTeamID := mi.Server.AccessAgentByID(sender).GetTeamID()
+		existingTeamID := mi.Server.GetAgentTeamID(sender)
 		mi.joinExistingTeam(existingTeamID)
 	} else {
 		mi.createNewTeam(sender)
@@ -327,6 +350,14 @@ func (mi *ExtendedAgent) HandleScoreReportMessage(msg *common.ScoreReportMessage
 	// Team's agent should implement logic to store or process score of other agents as desired
 }
 
+func (mi *ExtendedAgent) HandleThresholdNotificationMessage(msg *common.ThresholdNotificationMessage) {
+	if mi.VerboseLevel > 8 {
+		log.Printf("Agent %s was told the threshold of turn %d is between %d and %d (applied: %t)\n",
+			mi.GetID(), msg.Turn, msg.Min, msg.Max, msg.Applied)
+	}
+	mi.thresholdInfo = msg
+}
+
 func (mi *ExtendedAgent) HandleWithdrawalMessage(msg *common.WithdrawalMessage) {
 	if mi.VerboseLevel > 8 {
 		log.Printf("Agent %s received withdrawal notification from %s: amount=%d\n",
@@ -342,7 +373,7 @@ func (mi *ExtendedAgent) HandleAgentOpinionRequestMessage(msg *common.AgentOpini
 	opinion := 70
 	opinionResponseMsg := mi.CreateAgentOpinionResponseMessage(msg.AgentID, opinion)
 	log.Printf("Sending opinion response to %s\n", msg.AgentID)
-	mi.SendMessage(opinionResponseMsg, msg.AgentID) // Sent asynchronously, because this is "extra information"
+	mi.SendSynchronousMessage(opinionResponseMsg, msg.AgentID) // Sent synchronously so that seeded runs are reproducible
 }
 
 func (mi *ExtendedAgent) HandleAgentOpinionResponseMessage(msg *common.AgentOpinionResponseMessage) {
@@ -434,15 +465,6 @@ func (mi *ExtendedAgent) CreateAgentOpinionResponseMessage(agentID uuid.UUID, op
 
 // ----------------------- Debug functions -----------------------
 
-func Roll3Dice() int {
-	// row 3d6
-	total := 0
-	for i := 0; i < 3; i++ {
-		total += rand.Intn(6) + 1
-	}
-	return total
-}
-
 // func Debug_StickOrAgainJudgement() bool {
 // 	// 50% chance to stick
 // 	return rand.Intn(2) == 0
@@ -473,7 +495,7 @@ func (mi *ExtendedAgent) DecideTeamForming(agentInfoList []common.ExposedAgentIn
 	}
 
 	// random choice from the invitation list
-	rand.Shuffle(len(invitationList), func(i, j int) { invitationList[i], invitationList[j] = invitationList[j], invitationList[i] })
+	mi.rng.Shuffle(len(invitationList), func(i, j int) { invitationList[i], invitationList[j] = invitationList[j], invitationList[i] })
 	if len(invitationList) == 0 {
 		return []uuid.UUID{}
 	}
@@ -556,24 +578,41 @@ func (mi *ExtendedAgent) VoteOnAgentEntry(candidateID uuid.UUID) bool {
 	return true
 }
 
+/*
+* Teams to apply to while the agent is an orphan, the most wanted first. Teams that
+* already rejected the agent are skipped by the server. The base agent does not pick
+* teams, so the server tries the teams of its preferred AoAs in order.
+ */
+func (mi *ExtendedAgent) GetTeamApplications() []uuid.UUID {
+	return nil
+}
+
 // ----------------------- Data Recording Functions -----------------------
 func (mi *ExtendedAgent) RecordAgentStatus(instance common.IExtendedAgent) gameRecorder.AgentRecord {
 	record := gameRecorder.NewAgentRecord(
 		instance.GetID(),
 		instance.GetTrueSomasTeamID(),
 		instance.GetTrueScore(),
-		instance.GetStatedContribution(instance),
-		instance.GetActualContribution(instance),
-		instance.GetActualWithdrawal(instance),
-		instance.GetStatedWithdrawal(instance),
+		// contributions and withdrawals are filled in by the server from the turn, asking
+		// the agent again could give different values from the ones applied
 		instance.GetTeamID(),
-		"1",
+		"", // filled in by the server from the turn's events
 	)
 	return record
 }
 
 // ----------------------- Team 2 AoA Functions -----------------------
 
+/*
+Provide agentId for memory, current accumulated score
+(to see if above or below predicted threshold for common pool contribution)
+And previous roll in case relevant
+*/
+func (mi *ExtendedAgent) StickOrAgainFor(agentId uuid.UUID, accumulatedScore int, prevRoll int) int {
+	// random chance, to simulate what is already implemented
+	return mi.rng.Intn(2)
+}
+
 func (mi *ExtendedAgent) Team2_GetLeaderVote() common.Vote {
 	log.Printf("[WARNING] Base Leader Vote Function Called")
 	// Shouldn't happen, but if it does, then vote for yourself
@@ -588,7 +627,7 @@ func (mi *ExtendedAgent) Team2_GetLeaderVote() common.Vote {
 	}
 
 	// Randomly select a leader
-	leader := agentsInTeam[rand.Intn(len(agentsInTeam))]
+	leader := agentsInTeam[mi.rng.Intn(len(agentsInTeam))]
 
 	return common.CreateVote(1, mi.GetID(), leader)
 }
@@ -2,7 +2,6 @@ package agents
 
 import (
 	"log"
-	"math/rand"
 
 	common "github.com/ADimoska/SOMASExtended/common"
 
@@ -40,7 +39,7 @@ func (mi *MI_256_v1) DecideTeamForming(agentInfoList []common.ExposedAgentInfo)
 
 	// TODO: implement team forming logic
 	// random choice from the invitation list
-	rand.Shuffle(len(invitationList), func(i, j int) { invitationList[i], invitationList[j] = invitationList[j], invitationList[i] })
+	mi.rng.Shuffle(len(invitationList), func(i, j int) { invitationList[i], invitationList[j] = invitationList[j], invitationList[i] })
 	chosenAgent := invitationList[0]
 
 	// Return a slice containing the chosen agent
@@ -87,7 +86,7 @@ func (mi *MI_256_v1) Team4_GetRankUpVote() map[uuid.UUID]int {
 	rankUpVote := make(map[uuid.UUID]int)
 
 	for _, agentId := range agentsInTeam {
-		rankUpVote[agentId] = rand.Intn(2)
+		rankUpVote[agentId] = mi.rng.Intn(2)
 	}
 
 	log.Println(rankUpVote)
@@ -104,7 +103,7 @@ func (mi *MI_256_v1) Team4_GetProposedWithdrawalVote() map[uuid.UUID]int {
 	proposedWithdrawals := make(map[uuid.UUID]int)
 
 	for _, agentId := range agentsInTeam {
-		proposedWithdrawals[agentId] = rand.Intn(2)
+		proposedWithdrawals[agentId] = mi.rng.Intn(2)
 	}
 
 	log.Println(proposedWithdrawals)
@@ -131,7 +130,7 @@ func (mi *MI_256_v1) Team4_GetPunishmentVoteMap() map[int]int {
 	punishmentVoteMap := make(map[int]int)
 
 	for punishment := 0; punishment <= 4; punishment++ {
-		punishmentVoteMap[punishment] = rand.Intn(5)
+		punishmentVoteMap[punishment] = mi.rng.Intn(5)
 	}
 
 	return punishmentVoteMap
@@ -62,7 +62,7 @@ const min_stated_withdrawal = 1    //minimum withdrawal stated by cheater
 const cheat_amount = 3             //how much stated & actually contributed or withdrawn if cheating
 
 func (a1 *Team1Agent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
-	exp := getExpectedGain(accumulatedScore, prevRoll)
+	exp := a1.Server.GetResourceGame().ExpectedGain(accumulatedScore, prevRoll)
 	if exp < 2.0 {
 		return true
 	} else {
@@ -71,43 +71,9 @@ func (a1 *Team1Agent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
 
 }
 
-func getExpectedGain(accumulatedScore, prevRoll int) float64 {
-	lookup := make(map[int]int)
-	for i := 1; i <= 6; i++ {
-		for j := 1; j <= 6; j++ {
-			for k := 1; k <= 6; k++ {
-				sum := i + j + k
-				lookup[sum]++
-			}
-		}
-	}
-
-	prob := make([]float64, 19)
-	var totalCombinations int
-	for _, v := range lookup {
-		totalCombinations += v
-	}
-	for k, v := range lookup {
-		prob[k] = float64(v) / float64(totalCombinations)
-	}
-
-	var pLoss, eLoss, eGain float64
-
-	for i := 3; i <= prevRoll; i++ {
-		pLoss += prob[i]
-	}
-	eLoss = pLoss * float64(accumulatedScore) * -1
-
-	for i := prevRoll + 1; i < 19; i++ {
-		eGain += prob[i] * float64(i)
-	}
-
-	return eGain + eLoss
-}
-
 func (a1 *Team1Agent) GetActualContribution(instance common.IExtendedAgent) int {
 	if a1.HasTeam() {
-		aoaExpectedContribution := a1.Server.GetTeam(a1.GetID()).TeamAoA.GetExpectedContribution(a1.GetID(), a1.Score)
+		aoaExpectedContribution := a1.Server.GetTeamAoA().GetExpectedContribution(a1.GetID(), a1.Score)
 		switch a1.agentType {
 		case Honest, CheatLongTerm:
 			return aoaExpectedContribution
@@ -129,15 +95,15 @@ func (a1 *Team1Agent) GetActualContribution(instance common.IExtendedAgent) int
 
 func (a1 *Team1Agent) GetActualWithdrawal(instance common.IExtendedAgent) int {
 	if a1.HasTeam() {
-		commonPool := a1.Server.GetTeam(a1.GetID()).GetCommonPool()
-		aoaExpectedWithdrawal := a1.Server.GetTeam(a1.GetID()).TeamAoA.GetExpectedWithdrawal(a1.GetID(), a1.Score, commonPool)
+		commonPool := a1.Server.GetTeamCommonPool()
+		aoaExpectedWithdrawal := a1.Server.GetTeamAoA().GetExpectedWithdrawal(a1.GetID(), a1.Score, commonPool)
 		currentRank := 0
 		switch a1.agentType {
 		case Honest:
 			return aoaExpectedWithdrawal
 		case CheatLongTerm:
 			// Perform type assertion to get Team1AoA
-			teamAoA, ok := a1.Server.GetTeam(a1.GetID()).TeamAoA.(*common.Team1AoA)
+			teamAoA, ok := a1.Server.GetTeamAoA().(common.IAgentRankView)
 			if ok {
 				currentRank = teamAoA.GetAgentNewRank(a1.GetID())
 				if currentRank > 1 {
@@ -208,7 +174,7 @@ func (a *Team1Agent) GetAoARanking() []int {
 func (a1 *Team1Agent) hasClimbedRankAndWithdrawn() bool {
 	if a1.HasTeam() {
 		// Access Team1AoA and check rank changes or over-withdrawals
-		teamAoA, ok := a1.Server.GetTeam(a1.GetID()).TeamAoA.(*common.Team1AoA)
+		teamAoA, ok := a1.Server.GetTeamAoA().(common.IAgentRankView)
 		if !ok {
 			return false // If unable to access Team1AoA, assume no rank climb
 		}
@@ -238,7 +204,8 @@ func (a1 *Team1Agent) GetContributionAuditVote() common.Vote {
 
 		// Iterate over memory to find the agent with suspiciously high contributions
 		// Can be improved by adding a check to compare true common pool value with stated contribution
-		for agentID, memoryEntry := range a1.memory {
+		for _, agentID := range common.SortedKeys(a1.memory) {
+			memoryEntry := a1.memory[agentID]
 			// Limit by the last contributions
 			relevantContributions := memoryEntry.historyContribution[:memoryEntry.LastContributionCount]
 			for _, contribution := range relevantContributions {
@@ -275,7 +242,8 @@ func (a1 *Team1Agent) GetWithdrawalAuditVote() common.Vote {
 		highestDiscrepancy := 0
 
 		// Iterate over memory to find the agent with the largest discrepancy
-		for agentID, memoryEntry := range a1.memory {
+		for _, agentID := range common.SortedKeys(a1.memory) {
+			memoryEntry := a1.memory[agentID]
 			relevantWithdrawals := memoryEntry.historyWithdrawal[:memoryEntry.LastWithdrawalCount]
 			for _, withdrawal := range relevantWithdrawals {
 				discrepancy := withdrawal.Data2 - withdrawal.Data1 //expected - stated
@@ -308,6 +276,31 @@ func Create_Team1Agent(funcs baseAgent.IExposedServerFunctions[common.IExtendedA
 	}
 }
 
+// Forget the history of the other agents as far as the memory policy says, keeping the
+// most recent entries
+func (a1 *Team1Agent) ResetForIteration(policy common.MemoryPolicy) {
+	a1.ExtendedAgent.ResetForIteration(policy)
+	if !policy.Keeps() {
+		a1.memory = make(map[uuid.UUID]AgentMemory)
+		return
+	}
+	for agentID, memoryEntry := range a1.memory {
+		memoryEntry.honestyScore = policy.RetainInt(memoryEntry.honestyScore, 0)
+		memoryEntry.historyContribution, memoryEntry.LastContributionCount = keepRecent(memoryEntry.historyContribution, memoryEntry.LastContributionCount, policy)
+		memoryEntry.historyWithdrawal, memoryEntry.LastWithdrawalCount = keepRecent(memoryEntry.historyWithdrawal, memoryEntry.LastWithdrawalCount, policy)
+		memoryEntry.historyScore, memoryEntry.LastScoreCount = keepRecent(memoryEntry.historyScore, memoryEntry.LastScoreCount, policy)
+		a1.memory[agentID] = memoryEntry
+	}
+}
+
+// The most recent entries of the first count entries of a history that the policy keeps,
+// and how many there are
+func keepRecent(history []Pair, count int, policy common.MemoryPolicy) ([]Pair, int) {
+	count = min(count, len(history))
+	kept := policy.RecentCount(count)
+	return append([]Pair{}, history[count-kept:count]...), kept
+}
+
 // ----------------- Messaging functions -----------------------
 
 func (mi *Team1Agent) HandleContributionMessage(msg *common.ContributionMessage) {
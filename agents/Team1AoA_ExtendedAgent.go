@@ -17,8 +17,13 @@ func (mi *ExtendedAgent) Team1_ChairUpdateRanks(currentRanking map[uuid.UUID]int
 	// and gets the new ranks of the agents in the team
 	// according to AoA function
 	newRanking := make(map[uuid.UUID]int)
+	teamAoA, ok := mi.Server.GetTeamAoA().(common.IAgentRankView)
+	if !ok {
+		// the team no longer ranks its members
+		return currentRanking
+	}
 	for agentUUID := range currentRanking {
-		newRank := mi.Server.GetTeam(agentUUID).TeamAoA.(*common.Team1AoA).GetAgentNewRank(agentUUID)
+		newRank := teamAoA.GetAgentNewRank(agentUUID)
 		newRanking[agentUUID] = newRank
 	}
 
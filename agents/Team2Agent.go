@@ -23,6 +23,8 @@ type Team2Agent struct {
 	statedWithdrawal   map[uuid.UUID]int
 	thresholdBounds    []int
 	commonPoolEstimate int
+	// score each teammate died with this iteration, told by the server
+	deadTeammateScores map[uuid.UUID]int
 }
 
 // constructor for team2agent - initialised as all followers
@@ -40,15 +42,38 @@ func Team2_CreateAgent(funcs agent.IExposedServerFunctions[common.IExtendedAgent
 		statedWithdrawal:   make(map[uuid.UUID]int),
 		thresholdBounds:    make([]int, 2),
 		commonPoolEstimate: 0,
+		deadTeammateScores: make(map[uuid.UUID]int),
 	}
 }
 
 // Part 1: Specialised Agent Strategy Functions
 
 // ---------- TRUST SCORE SYSTEM ----------
+
+// trust in an agent we know nothing about
+const initialTrustScore = 70
+
 func (t2a *Team2Agent) SetTrustScore(agentID uuid.UUID) {
 	// Initialize trust score for this agent
-	t2a.trustScore[agentID] = 70
+	t2a.trustScore[agentID] = initialTrustScore
+}
+
+// Trust and strikes fade back towards those of a stranger as far as the memory policy
+// says, what was stated last iteration is only kept if memory is
+func (t2a *Team2Agent) ResetForIteration(policy common.MemoryPolicy) {
+	t2a.ExtendedAgent.ResetForIteration(policy)
+	for agentID, trust := range t2a.trustScore {
+		t2a.trustScore[agentID] = policy.RetainInt(trust, initialTrustScore)
+	}
+	for agentID, strikes := range t2a.strikeCount {
+		t2a.strikeCount[agentID] = policy.RetainInt(strikes, 0)
+	}
+	if !policy.Keeps() {
+		t2a.statedContribution = make(map[uuid.UUID]int)
+		t2a.statedWithdrawal = make(map[uuid.UUID]int)
+		t2a.thresholdBounds = make([]int, 2)
+		t2a.commonPoolEstimate = 0
+	}
 }
 
 func (t2a *Team2Agent) getAverageTeamTrustScore(teamID uuid.UUID) int {
@@ -257,7 +282,7 @@ func (t2a *Team2Agent) HandleTeamFormationMessage(msg *common.TeamFormationMessa
 		// Handle team creation/joining based on sender's team status
 		sender := msg.GetSender()
 		if t2a.Server.CheckAgentAlreadyInTeam(sender) {
-			existingTeamID := t2a.Server.AccessAgentByID(sender).GetTeamID()
+			existingTeamID := t2a.Server.GetAgentTeamID(sender)
 			t2a.joinExistingTeam(existingTeamID)
 		} else {
 			t2a.createNewTeam(sender)
@@ -280,7 +305,7 @@ func (t2a *Team2Agent) HandleAgentOpinionRequestMessage(msg *common.AgentOpinion
 	trustScore = t2a.trustScore[agentID]
 	agentOpinionResponseMessage := t2a.CreateAgentOpinionResponseMessage(agentID, trustScore)
 
-	t2a.SendMessage(agentOpinionResponseMessage, msg.GetSender()) // Respond to the person asking with a trust score, asynchronously
+	t2a.SendSynchronousMessage(agentOpinionResponseMessage, msg.GetSender()) // Respond to the person asking with a trust score, synchronously so that seeded runs are reproducible
 }
 
 func (t2a *Team2Agent) HandleAgentOpinionResponseMessage(msg *common.AgentOpinionResponseMessage) {
@@ -311,6 +336,16 @@ func (t2a *Team2Agent) VoteOnAgentEntry(candidateID uuid.UUID) bool {
 
 // ---------- DECISION TO STICK  ----------
 
+// Remember the score of each teammate that dies, to guess the threshold from
+func (t2a *Team2Agent) OnTeammateDied(agentID uuid.UUID, score int) {
+	t2a.deadTeammateScores[agentID] = score
+}
+
+// Teams are formed again every iteration, so forget the dead teammates
+func (t2a *Team2Agent) OnIterationEnd(iteration int) {
+	t2a.deadTeammateScores = make(map[uuid.UUID]int)
+}
+
 // Function to retrieve ID and Score of all dead agents in team
 func (t2a *Team2Agent) GetDeadTeammates() []struct {
 	AgentID uuid.UUID
@@ -322,22 +357,15 @@ func (t2a *Team2Agent) GetDeadTeammates() []struct {
 		Score   int
 	}, 0)
 
-	// Get the IDs of agents in the same team
-	for _, agentID := range t2a.Server.GetAgentsInTeam(t2a.TeamID) {
-		// Skip the agent is the current agent or if the agent is not dead
-		if t2a.GetID() != agentID && t2a.Server.IsAgentDead(agentID) {
-			// Get the score of the dead agent
-			score := t2a.Server.GetAgentKilledScore(agentID)
-
-			// Append the agent's ID and score to the result slice
-			deadTeammates = append(deadTeammates, struct {
-				AgentID uuid.UUID
-				Score   int
-			}{
-				AgentID: agentID,
-				Score:   score,
-			})
-		}
+	for _, agentID := range common.SortedKeys(t2a.deadTeammateScores) {
+		// Append the agent's ID and score to the result slice
+		deadTeammates = append(deadTeammates, struct {
+			AgentID uuid.UUID
+			Score   int
+		}{
+			AgentID: agentID,
+			Score:   t2a.deadTeammateScores[agentID],
+		})
 	}
 
 	return deadTeammates
@@ -345,26 +373,12 @@ func (t2a *Team2Agent) GetDeadTeammates() []struct {
 
 // Function to determine the probability of improvement of the next re-roll compared to previous roll
 func (t2a *Team2Agent) probabilityOfImprovement(prevRoll int) float64 {
-	if prevRoll == 0 { // First roll of the iteration so guaranteed probability of improvement
+	if prevRoll < 0 { // First roll of the turn so guaranteed probability of improvement
 		return 1
 	}
 
-	// Probabilities of sums with 3 dice (precomputed distribution of 3d6 outcomes)
-	probabilities := map[int]float64{
-		3: 1.0 / 216, 4: 3.0 / 216, 5: 6.0 / 216, 6: 10.0 / 216,
-		7: 15.0 / 216, 8: 21.0 / 216, 9: 25.0 / 216, 10: 27.0 / 216,
-		11: 27.0 / 216, 12: 25.0 / 216, 13: 21.0 / 216, 14: 15.0 / 216,
-		15: 10.0 / 216, 16: 6.0 / 216, 17: 3.0 / 216, 18: 1.0 / 216,
-	}
-
-	// Calculate the cumulative probability of rolling higher than `prevRoll`
-	cumulativeProbability := 0.0
-	for outcome := prevRoll + 1; outcome <= 18; outcome++ {
-		cumulativeProbability += probabilities[outcome]
-	}
-
-	return cumulativeProbability
-
+	// Probability of rolling higher than `prevRoll`
+	return 1 - t2a.Server.GetResourceGame().BustProbability(prevRoll)
 }
 
 // Function to determine risk tolerance which determines how risk averse or risky agent should be
@@ -409,6 +423,7 @@ func (t2a *Team2Agent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
 	// Determine cumulative probability of improvement
 	cumulativeProbability := t2a.probabilityOfImprovement(prevRoll)
 	log.Printf("*****Cumulative Probability of Improvement: %.2f\n", cumulativeProbability)
+	maxRoll := float64(t2a.Server.GetResourceGame().MaxRoll())
 
 	log.Printf("*****Rank is: %t\n", t2a.rank) // true is leader, false is citizen
 
@@ -419,7 +434,7 @@ func (t2a *Team2Agent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
 	if t2a.rank { // Leader is very risky and has a fixed riskTolerance of 0.8
 		riskTolerance = 0.8
 		threshold := float64(prevRoll) * (1.0 - riskTolerance)
-		if (cumulativeProbability * 18) > threshold {
+		if (cumulativeProbability * maxRoll) > threshold {
 			log.Printf("*****Decision: Re-roll\n")
 			return false // Re-roll
 		}
@@ -429,8 +444,8 @@ func (t2a *Team2Agent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
 		// If low risk tolerance then higher threshold hence less likely to re-roll
 		threshold := float64(prevRoll) * (1.0 - riskTolerance)
 		log.Printf("*****Citizen threshold: %f\n", threshold)
-		log.Printf("*****Cumulative Probability * 18: %f\n", (cumulativeProbability * 18))
-		if (cumulativeProbability * 18) > threshold {
+		log.Printf("*****Cumulative Probability * max roll: %f\n", (cumulativeProbability * maxRoll))
+		if (cumulativeProbability * maxRoll) > threshold {
 			log.Printf("*****Decision: Re-roll\n")
 			return false // Re-roll
 		}
@@ -455,6 +470,7 @@ func (t2a *Team2Agent) StickOrAgainFor(agentId uuid.UUID, accumulatedScore int,
 	// Determine cumulative probability of improvement
 	cumulativeProbability := t2a.probabilityOfImprovement(prevRoll)
 	log.Printf("*****Cumulative Probability of Improvement: %.2f\n", cumulativeProbability)
+	maxRoll := float64(t2a.Server.GetResourceGame().MaxRoll())
 
 	log.Printf("*****Rank is: %t\n", t2a.rank) // true is leader, false is citizen
 
@@ -465,7 +481,7 @@ func (t2a *Team2Agent) StickOrAgainFor(agentId uuid.UUID, accumulatedScore int,
 	// Leader is very risky and has a fixed riskTolerance of 0.8
 	riskTolerance = 0.8
 	threshold := float64(prevRoll) * (1.0 - riskTolerance)
-	if (cumulativeProbability * 18) > threshold {
+	if (cumulativeProbability * maxRoll) > threshold {
 		log.Printf("*****Decision: Re-roll\n")
 		return 0 // Re-roll
 	}
@@ -486,9 +502,25 @@ func (t2a *Team2Agent) ThresholdGuessStrategy() int {
 	// Ensures agents can survive until threshold is applied and dead agents can be used to guess threshold from
 	initialThresholdGuess := 10000
 
+	// Add a margin of error to ensure threshold guess is above forecasted threshold
+	marginOfError := 10
+
+	// If the server announced the coming threshold, the upper bound is a safe guess
+	if t2a.thresholdInfo != nil && !t2a.thresholdInfo.Applied {
+		return t2a.thresholdInfo.Max
+	}
+	// The threshold tends to grow, so the last one applied is a lower bound for the guess
+	lowestGuess := 0
+	if t2a.thresholdInfo != nil {
+		lowestGuess = t2a.thresholdInfo.Max + marginOfError
+	}
+
 	deadTeammates := t2a.GetDeadTeammates()
 
 	if len(deadTeammates) == 0 {
+		if lowestGuess > 0 {
+			return lowestGuess
+		}
 		return initialThresholdGuess // No valid threshold guess for now
 	}
 
@@ -504,11 +536,8 @@ func (t2a *Team2Agent) ThresholdGuessStrategy() int {
 	agentAliveScore := t2a.GetTrueScore()
 
 	// Calculate the new threshold guess by taking the midpoint of maxDeadScore and agentAliveScore
-	// Add a margin of error to ensure threshold guess is above forecasted threshold
-	marginOfError := 10
-
 	thresholdGuess := ((maxDeadScore + agentAliveScore) / 2) + marginOfError
-	return thresholdGuess
+	return max(thresholdGuess, lowestGuess)
 }
 
 // ---------- CONTRIBUTION, WITHDRAWAL AND ASSOCIATED AUDITING ----------
@@ -523,8 +552,9 @@ func (t2a *Team2Agent) GetActualContribution(instance common.IExtendedAgent) int
 		return 0
 	}
 
-	switch aoa := t2a.Server.GetTeam(t2a.GetID()).TeamAoA.(type) {
-	case *common.Team2AoA:
+	aoa := t2a.Server.GetTeamAoA()
+	switch aoa.GetAoAID() {
+	case 2:
 		// under our own AoA, for now we just return what is expected of us.
 
 		// get the contribution we are expected to make
@@ -606,7 +636,7 @@ func (t2a *Team2Agent) GetContributionAuditVote() common.Vote {
 	// Step 2: If there is no one obvious to audit based on stated contributions, then:
 	// get the actual size of common pool post contributions, and the supposed size based on what agents have stated about their contributions.
 	// compare them to find the discrepancy.
-	var actualCommonPoolSize = t2a.Server.GetTeam(t2a.GetID()).GetCommonPool()
+	var actualCommonPoolSize = t2a.Server.GetTeamCommonPool()
 	var discrepancy int = t2a.commonPoolEstimate - actualCommonPoolSize
 
 	// after finding discrepancy, set our common pool estimate to the actual size of the common pool in preparation for withdrawal stage
@@ -661,10 +691,11 @@ func (t2a *Team2Agent) GetActualWithdrawal(instance common.IExtendedAgent) int {
 		return 0
 	}
 
-	commonPool := t2a.Server.GetTeam(t2a.GetID()).GetCommonPool()
+	commonPool := t2a.Server.GetTeamCommonPool()
 
-	switch aoa := t2a.Server.GetTeam(t2a.GetID()).TeamAoA.(type) {
-	case *common.Team2AoA:
+	aoa := t2a.Server.GetTeamAoA()
+	switch aoa.GetAoAID() {
+	case 2:
 		// under our own AoA, for now we just withdraw what is expected of us.
 
 		aoaExpectedWithdrawal := aoa.GetExpectedWithdrawal(t2a.GetID(), t2a.GetTrueScore(), commonPool)
@@ -738,11 +769,11 @@ func (t2a *Team2Agent) GetWithdrawalAuditVote() common.Vote {
 
 	// get the actual size of common pool after withdrawals, and the supposed size based on what agents have stated about their withdrawals.
 	// compare them to find the discrepancy.
-	var actualCommonPoolSize = t2a.Server.GetTeam(t2a.GetID()).GetCommonPool()
+	var actualCommonPoolSize = t2a.Server.GetTeamCommonPool()
 	var discrepancy int = t2a.commonPoolEstimate - actualCommonPoolSize
 
 	// reset to commonpoolestimate after withdrawal
-	t2a.commonPoolEstimate = t2a.Server.GetTeam(t2a.GetID()).GetCommonPool()
+	t2a.commonPoolEstimate = t2a.Server.GetTeamCommonPool()
 
 	// if there is a significant discrepancy, decrement all your teams trust scores by a suspicion factor.
 	// then check to see if the least trusted agent in your team is below the threshold
@@ -791,12 +822,12 @@ func (t2a *Team2Agent) sendOpinionMessages(agentID uuid.UUID, numAgents int) {
 
 	entries := make([]entry, 0, len(t2a.trustScore))
 
-	for id, score := range t2a.trustScore {
-		entries = append(entries, entry{Key: id, Value: score})
+	for _, id := range common.SortedKeys(t2a.trustScore) {
+		entries = append(entries, entry{Key: id, Value: t2a.trustScore[id]})
 	}
 
 	// Sort in decreasing order of trust score (most trusted first)
-	sort.Slice(entries, func(i, j int) bool {
+	sort.SliceStable(entries, func(i, j int) bool {
 		return entries[i].Value > entries[j].Value
 	})
 
@@ -812,7 +843,7 @@ func (t2a *Team2Agent) sendOpinionMessages(agentID uuid.UUID, numAgents int) {
 		if receiver == agentID {
 			continue // Don't ask an agent for its opinion of itself
 		}
-		t2a.SendMessage(agentOpinionMessage, entries[i].Key)
+		t2a.SendSynchronousMessage(agentOpinionMessage, entries[i].Key)
 	}
 }
 
@@ -822,14 +853,13 @@ func (t2a *Team2Agent) GetTeamRanking() []uuid.UUID {
 	teamIDs := t2a.Server.GetTeamIDs()
 	ranking := make(map[uuid.UUID]int)
 
-	// Calculate composite scores for each team
+	// Rank teams by trust score, the common pools of other teams are not visible
 	for _, teamID := range teamIDs {
-		commonPool := t2a.Server.GetTeamCommonPool(teamID)
 		trustScore := t2a.getAverageTeamTrustScore(teamID)
 		if trustScore == 0 {
 			continue // Skip teams with no trust score -> Likely means they are empty or very bad in general
 		}
-		ranking[teamID] = int((float64(commonPool)*2.0 + float64(trustScore)) / 3.0)
+		ranking[teamID] = trustScore
 	}
 
 	// Convert the map to a slice of key-value pairs
@@ -838,12 +868,12 @@ func (t2a *Team2Agent) GetTeamRanking() []uuid.UUID {
 		Score int
 	}
 	var teamScores []teamScore
-	for id, score := range ranking {
-		teamScores = append(teamScores, teamScore{ID: id, Score: score})
+	for _, id := range common.SortedKeys(ranking) {
+		teamScores = append(teamScores, teamScore{ID: id, Score: ranking[id]})
 	}
 
 	// Sort the slice by composite score in descending order
-	sort.Slice(teamScores, func(i, j int) bool {
+	sort.SliceStable(teamScores, func(i, j int) bool {
 		return teamScores[i].Score > teamScores[j].Score
 	})
 
@@ -903,7 +933,7 @@ func (t2a *Team2Agent) GetTeamRanking() []uuid.UUID {
 // 	// Agent-specific variables
 // 	agentID := t2a.GetID()
 // 	agentScore := t2a.score
-// 	commonPool := t2a.Server.GetTeam(t2a.GetID()).GetCommonPool()
+// 	commonPool := t2a.Server.GetTeamCommonPool()
 
 // 	// Expected withdrawal from AoA
 // 	aoaWithdrawal := t2a.Server.GetTeam(t2a.TeamID).TeamAoA.(*common.Team2AoA).GetExpectedWithdrawal(t2a.GetID(), agentScore, commonPool)
@@ -4,7 +4,6 @@ package agents
 
 import (
 	"log"
-	"math/rand"
 
 	"github.com/google/uuid"
 
@@ -52,7 +51,10 @@ func (mi *ExtendedAgent) Team4_GetProposedWithdrawal(instance common.IExtendedAg
 		return 0
 	}
 	// Currently, assume stated withdrawal matches actual withdrawal
-	return instance.Team4_ProposeWithdrawal()
+	if proposer, ok := instance.(common.IRankedWithdrawalAgent); ok {
+		return proposer.Team4_ProposeWithdrawal()
+	}
+	return mi.Team4_ProposeWithdrawal()
 }
 
 func (mi *ExtendedAgent) Team4_StateProposalToTeam() {
@@ -67,14 +69,14 @@ func (mi *ExtendedAgent) Team4_ProposeWithdrawal() int {
 	if !mi.HasTeam() {
 		return 0
 	}
-	if mi.Server.GetTeam(mi.GetID()).TeamAoA != nil {
+	if mi.Server.GetTeamAoA() != nil {
 		// double check if score in agent is sufficient (this should be handled by AoA though)
-		commonPool := mi.Server.GetTeam(mi.GetID()).GetCommonPool()
-		aoaExpectedWithdrawal := mi.Server.GetTeam(mi.GetID()).TeamAoA.GetExpectedWithdrawal(mi.GetID(), mi.GetTrueScore(), commonPool)
+		commonPool := mi.Server.GetTeamCommonPool()
+		aoaExpectedWithdrawal := mi.Server.GetTeamAoA().GetExpectedWithdrawal(mi.GetID(), mi.GetTrueScore(), commonPool)
 		if commonPool < aoaExpectedWithdrawal {
 			return commonPool
 		}
-		return aoaExpectedWithdrawal + rand.Intn(4)
+		return aoaExpectedWithdrawal + mi.rng.Intn(4)
 	} else {
 		if mi.VerboseLevel > 6 {
 			log.Printf("[WARNING] Agent %s has no AoA, withdrawing 0\n", mi.GetID())
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+)
+
+// Regenerates the visualisation and CSVs from a game record written by the main simulation
+func main() {
+	recordPath := flag.String("record", "visualization_output/game_record.jsonl", "path to the JSON Lines game record")
+	csvDir := flag.String("csv", "visualization_output/csv_data", "directory the CSVs are exported to (empty to skip)")
+	flag.Parse()
+
+	recorder, err := gameRecorder.LoadJSONL(*recordPath)
+	if err != nil {
+		log.Fatalf("Failed to load game record: %v", err)
+	}
+	log.Printf("Loaded %d turns and %d events from %s\n", len(recorder.TurnRecords), len(recorder.Events), *recordPath)
+
+	gameRecorder.CreatePlaybackHTML(recorder)
+	if *csvDir != "" {
+		if err := gameRecorder.ExportToCSV(recorder, *csvDir); err != nil {
+			log.Fatalf("Failed to export CSVs: %v", err)
+		}
+	}
+}
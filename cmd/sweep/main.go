@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	sweep "github.com/ADimoska/SOMASExtended/sweep"
+)
+
+// Runs every combination of a parameter grid over several seeds and writes one summary row per combination
+func main() {
+	sweepPath := flag.String("sweep", "scenarios/sweep.yaml", "path to a YAML or JSON sweep file")
+	output := flag.String("output", "", "path of the CSV summary (overrides the sweep file)")
+	flag.Parse()
+
+	sweepConfig, err := sweep.LoadSweepConfig(*sweepPath)
+	if err != nil {
+		log.Fatalf("Failed to load sweep: %v", err)
+	}
+	if *output != "" {
+		sweepConfig.Output = *output
+	}
+
+	start := time.Now()
+	results, err := sweep.Run(sweepConfig)
+	// the sweep silences the simulation logs, so report on stderr
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sweep failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Ran %d cells in %v\n", len(results), time.Since(start).Round(time.Millisecond))
+
+	if err := sweep.WriteSummary(os.Stdout, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print summary: %v\n", err)
+		os.Exit(1)
+	}
+	if sweepConfig.Output != "" {
+		if err := sweep.WriteSummaryFile(sweepConfig.Output, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write summary: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// AoAConstructor creates a fresh AoA for a team that has just adopted it
+type AoAConstructor func(team *Team, server ITurnServer) IArticlesOfAssociation
+
+// AoAHook runs once the team has adopted the AoA (e.g. to elect a leader)
+type AoAHook func(team *Team, server ITurnServer)
+
+// AoARegistration describes an AoA that teams can vote for
+type AoARegistration struct {
+	// id agents use to rank the AoA, stored in Team.TeamAoAID
+	ID   int
+	Name string
+	// called whenever a team adopts the AoA
+	Constructor AoAConstructor
+	// run in order after the AoA is assigned to the team
+	PostCreate []AoAHook
+}
+
+var (
+	aoaRegistryLock sync.RWMutex
+	aoaRegistry     = make(map[int]AoARegistration)
+)
+
+// RegisterAoA makes an AoA available to the teams. The id must be positive and
+// not taken, 0 is kept for the fallback FixedAoA.
+func RegisterAoA(registration AoARegistration) error {
+	if registration.ID <= 0 {
+		return fmt.Errorf("AoA %q must have a positive id, got %d", registration.Name, registration.ID)
+	}
+	if registration.Constructor == nil {
+		return fmt.Errorf("AoA %d (%q) has no constructor", registration.ID, registration.Name)
+	}
+
+	aoaRegistryLock.Lock()
+	defer aoaRegistryLock.Unlock()
+	if existing, ok := aoaRegistry[registration.ID]; ok {
+		return fmt.Errorf("AoA id %d is already registered to %q", registration.ID, existing.Name)
+	}
+	aoaRegistry[registration.ID] = registration
+	return nil
+}
+
+// MustRegisterAoA is RegisterAoA for use in init functions
+func MustRegisterAoA(registration AoARegistration) {
+	if err := RegisterAoA(registration); err != nil {
+		panic(err)
+	}
+}
+
+// UnregisterAoA removes an AoA, so teams can no longer adopt it
+func UnregisterAoA(aoaID int) {
+	aoaRegistryLock.Lock()
+	defer aoaRegistryLock.Unlock()
+	delete(aoaRegistry, aoaID)
+}
+
+func GetAoARegistration(aoaID int) (AoARegistration, bool) {
+	aoaRegistryLock.RLock()
+	defer aoaRegistryLock.RUnlock()
+	registration, ok := aoaRegistry[aoaID]
+	return registration, ok
+}
+
+// RegisteredAoAIDs returns the ids of every registered AoA in increasing order,
+// this is the list of candidates agents rank
+func RegisteredAoAIDs() []int {
+	aoaRegistryLock.RLock()
+	defer aoaRegistryLock.RUnlock()
+	ids := make([]int, 0, len(aoaRegistry))
+	for id := range aoaRegistry {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// AdoptAoA creates the registered AoA for the team and runs its hooks. Unknown ids
+// fall back to the FixedAoA with id 0. Returns the id the team ended up with.
+func AdoptAoA(aoaID int, team *Team, server ITurnServer) int {
+	registration, ok := GetAoARegistration(aoaID)
+	if !ok {
+		team.TeamAoA = CreateFixedAoA(1, server.NewRand())
+		team.TeamAoAID = 0
+		return 0
+	}
+
+	team.TeamAoA = registration.Constructor(team, server)
+	team.TeamAoAID = registration.ID
+	for _, hook := range registration.PostCreate {
+		hook(team, server)
+	}
+	return registration.ID
+}
+
+func init() {
+	MustRegisterAoA(AoARegistration{
+		ID:   1,
+		Name: "Team1",
+		Constructor: func(team *Team, server ITurnServer) IArticlesOfAssociation {
+			return CreateTeam1AoA(team, server.NewRand())
+		},
+	})
+	MustRegisterAoA(AoARegistration{
+		ID:   2,
+		Name: "Team2",
+		Constructor: func(team *Team, server ITurnServer) IArticlesOfAssociation {
+			return CreateTeam2AoA(team, uuid.Nil, 5, server.NewRand())
+		},
+		PostCreate: []AoAHook{
+			func(team *Team, server ITurnServer) {
+				server.ElectNewLeader(team.TeamID)
+			},
+		},
+	})
+	MustRegisterAoA(AoARegistration{
+		ID:   3,
+		Name: "Team3",
+		Constructor: func(team *Team, server ITurnServer) IArticlesOfAssociation {
+			return CreateTeam3AoA(team)
+		},
+	})
+	MustRegisterAoA(AoARegistration{
+		ID:   4,
+		Name: "Team4",
+		Constructor: func(team *Team, server ITurnServer) IArticlesOfAssociation {
+			return CreateTeam4AoA(team)
+		},
+	})
+	MustRegisterAoA(AoARegistration{
+		ID:   5,
+		Name: "Team5",
+		Constructor: func(team *Team, server ITurnServer) IArticlesOfAssociation {
+			return CreateTeam5AoA(server.NewRand())
+		},
+	})
+	MustRegisterAoA(AoARegistration{
+		ID:   6,
+		Name: "Team6",
+		Constructor: func(team *Team, server ITurnServer) IArticlesOfAssociation {
+			return CreateTeam6AoA(server.NewRand())
+		},
+	})
+}
@@ -9,6 +9,9 @@ type Vote struct {
 	AuditDuration int
 }
 
+// IArticlesOfAssociation is the core interface implemented by every AoA. Behaviour that
+// only some AoAs have is described by the optional capability interfaces below, which
+// the server detects with type assertions.
 type IArticlesOfAssociation interface {
 	GetExpectedContribution(agentId uuid.UUID, agentScore int) int
 	GetExpectedWithdrawal(agentId uuid.UUID, agentScore int, commonPool int) int
@@ -19,17 +22,80 @@ type IArticlesOfAssociation interface {
 	GetWithdrawalAuditResult(agentId uuid.UUID) bool
 	SetContributionAuditResult(agentId uuid.UUID, agentScore int, agentActualContribution int, agentStatedContribution int)
 	GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID
-	RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent)
 	GetPunishment(agentScore int, agentId uuid.UUID) int
+}
+
+// IPreIterationAoA is implemented by AoAs that run logic once their team is formed, before the first turn
+type IPreIterationAoA interface {
+	RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent)
+}
 
-	// Team 4 AoA Specific Functions
-	Team4_SetRankUp(map[uuid.UUID]map[uuid.UUID]int)
-	Team4_RunProposedWithdrawalVote(map[uuid.UUID]int, map[uuid.UUID]map[uuid.UUID]int)
-	Team4_HandlePunishmentVote(map[uuid.UUID]map[int]int) int
-	RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent)
+// IRankedWithdrawalAoA is implemented by AoAs where agents vote each other up the ranks
+// after contributing and vote on each other's proposed withdrawals before withdrawing
+type IRankedWithdrawalAoA interface {
+	Team4_SetRankUp(rankUpVoteMap map[uuid.UUID]map[uuid.UUID]int)
+	Team4_RunProposedWithdrawalVote(proposedWithdrawalMap map[uuid.UUID]int, withdrawalVoteMap map[uuid.UUID]map[uuid.UUID]int)
+}
+
+// IPunishmentVoteAoA is implemented by AoAs where the team votes on the punishment of an audited agent
+type IPunishmentVoteAoA interface {
+	// returns the chosen punishment as a percentage of the agent's score
+	Team4_HandlePunishmentVote(punishmentVoteMap map[uuid.UUID]map[int]int) int
+}
+
+// IResourceAllocationAoA is implemented by AoAs that allocate the common pool before withdrawals
+type IResourceAllocationAoA interface {
 	ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int
 }
 
+// IRankedAoA is implemented by AoAs that give their members ranks
+type IRankedAoA interface {
+	// returns the rank of every member, as the AoA names them
+	GetRanks() map[uuid.UUID]string
+}
+
+// ILeaderElectionAoA is implemented by AoAs led by an elected leader
+type ILeaderElectionAoA interface {
+	GetLeader() uuid.UUID
+	SetLeader(leader uuid.UUID)
+}
+
+// AdmissionDecision is a team's answer to an orphan asking to join it
+type AdmissionDecision struct {
+	Admitted bool
+	// taken from the orphan's score and added to the common pool when it is admitted
+	EntryFee int
+}
+
+// IAdmissionAoA is implemented by AoAs that decide which orphans join their team. Teams
+// whose AoA does not implement it admit an orphan when a majority of the members vote
+// for it (see MajorityAdmission).
+type IAdmissionAoA interface {
+	// votes holds each member's vote on the orphan, majority is the share of yes votes
+	// the server requires by default
+	DecideAdmission(orphanID uuid.UUID, orphanScore int, votes map[uuid.UUID]bool, majority float32) AdmissionDecision
+}
+
+// IMemberAdmittedAoA is implemented by AoAs that keep state about the orphans they admit. The
+// server calls it once the orphan has joined the team, DecideAdmission itself changes nothing.
+type IMemberAdmittedAoA interface {
+	OnMemberAdmitted(agentID uuid.UUID)
+}
+
+// MajorityAdmission is true when at least the given share of the votes are yes
+func MajorityAdmission(votes map[uuid.UUID]bool, majority float32) bool {
+	if len(votes) == 0 {
+		return false
+	}
+	yes := 0
+	for _, vote := range votes {
+		if vote {
+			yes++
+		}
+	}
+	return float32(yes)/float32(len(votes)) >= majority
+}
+
 func CreateVote(isVote int, voterId uuid.UUID, votedForId uuid.UUID) Vote {
 	return Vote{
 		IsVote:     isVote,
@@ -37,3 +103,53 @@ func CreateVote(isVote int, voterId uuid.UUID, votedForId uuid.UUID) Vote {
 		VotedForID: votedForId,
 	}
 }
+
+// TeamAoAView is what the members of a team can read of its AoA (see IAgentServer.GetTeamAoA),
+// so that they can not change the AoA's records
+type TeamAoAView interface {
+	GetAoAID() int
+	GetExpectedContribution(agentId uuid.UUID, agentScore int) int
+	GetExpectedWithdrawal(agentId uuid.UUID, agentScore int, commonPool int) int
+}
+
+// IAgentRankView is implemented by the views of AoAs whose members climb ranks (Team1AoA)
+type IAgentRankView interface {
+	TeamAoAView
+	// the rank the agent's recent contributions earn it
+	GetAgentNewRank(agentId uuid.UUID) int
+}
+
+type teamAoAView struct {
+	aoaID int
+	aoa   IArticlesOfAssociation
+}
+
+type agentRankView struct {
+	teamAoAView
+	ranked interface{ GetAgentNewRank(agentId uuid.UUID) int }
+}
+
+// NewTeamAoAView returns the read-only view of the team's AoA
+func NewTeamAoAView(aoaID int, aoa IArticlesOfAssociation) TeamAoAView {
+	view := teamAoAView{aoaID: aoaID, aoa: aoa}
+	if ranked, ok := aoa.(interface{ GetAgentNewRank(agentId uuid.UUID) int }); ok {
+		return agentRankView{teamAoAView: view, ranked: ranked}
+	}
+	return view
+}
+
+func (v teamAoAView) GetAoAID() int {
+	return v.aoaID
+}
+
+func (v teamAoAView) GetExpectedContribution(agentId uuid.UUID, agentScore int) int {
+	return v.aoa.GetExpectedContribution(agentId, agentScore)
+}
+
+func (v teamAoAView) GetExpectedWithdrawal(agentId uuid.UUID, agentScore int, commonPool int) int {
+	return v.aoa.GetExpectedWithdrawal(agentId, agentScore, commonPool)
+}
+
+func (v agentRankView) GetAgentNewRank(agentId uuid.UUID) int {
+	return v.ranked.GetAgentNewRank(agentId)
+}
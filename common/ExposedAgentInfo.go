@@ -2,9 +2,13 @@ package common
 
 import (
 	"github.com/google/uuid"
+
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
 )
 
 type ExposedAgentInfo struct {
 	AgentUUID   uuid.UUID
 	AgentTeamID uuid.UUID
+	// filled in by the server, an agent can not vouch for itself
+	Reputation gameRecorder.Reputation
 }
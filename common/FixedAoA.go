@@ -2,13 +2,13 @@ package common
 
 import (
 	"math/rand"
-	"time"
 
 	"github.com/google/uuid"
 )
 
 type FixedAoA struct {
 	auditRecord *AuditRecord
+	rng         *rand.Rand
 }
 
 func (f *FixedAoA) GetExpectedContribution(agentId uuid.UUID, agentScore int) int {
@@ -72,15 +72,12 @@ func (f *FixedAoA) GetVoteResult(votes []Vote) uuid.UUID {
 }
 
 func (t *FixedAoA) GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID {
-	// Seed the random number generator to ensure different shuffles each time
-	rand.Seed(time.Now().UnixNano())
-
 	// Create a copy of the agentIDs to avoid modifying the original list
 	shuffledAgents := make([]uuid.UUID, len(agentIDs))
 	copy(shuffledAgents, agentIDs)
 
 	// Shuffle the agent list
-	rand.Shuffle(len(shuffledAgents), func(i, j int) {
+	t.rng.Shuffle(len(shuffledAgents), func(i, j int) {
 		shuffledAgents[i], shuffledAgents[j] = shuffledAgents[j], shuffledAgents[i]
 	})
 
@@ -91,25 +88,10 @@ func (t *FixedAoA) GetPunishment(agentScore int, agentId uuid.UUID) int {
 	return (agentScore * 25) / 100
 }
 
-func (t *FixedAoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent)     {}
-func (t *FixedAoA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {}
-
-func (f *FixedAoA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int {
-	return make(map[uuid.UUID]int)
-}
-
-func CreateFixedAoA(duration int) IArticlesOfAssociation {
+func CreateFixedAoA(duration int, rng *rand.Rand) IArticlesOfAssociation {
 	auditRecord := NewAuditRecord(duration)
 	return &FixedAoA{
 		auditRecord: auditRecord,
+		rng:         rng,
 	}
 }
-
-// Do nothing
-func (t *FixedAoA) Team4_SetRankUp(rankUpVoteMap map[uuid.UUID]map[uuid.UUID]int) {
-}
-func (t *FixedAoA) Team4_RunProposedWithdrawalVote(map[uuid.UUID]int, map[uuid.UUID]map[uuid.UUID]int) {
-}
-func (t *FixedAoA) Team4_HandlePunishmentVote(map[uuid.UUID]map[int]int) int {
-	return 0
-}
@@ -20,7 +20,7 @@ type IExtendedAgent interface {
 
 	// Functions that involve strategic decisions
 	StartTeamForming(instance IExtendedAgent, agentInfoList []ExposedAgentInfo)
-	StartRollingDice(instance IExtendedAgent)
+	SendTeamFormingInvitation(agentIDs []uuid.UUID)
 	GetActualContribution(instance IExtendedAgent) int
 	GetActualWithdrawal(instance IExtendedAgent) int
 	GetStatedContribution(instance IExtendedAgent) int
@@ -33,15 +33,28 @@ type IExtendedAgent interface {
 	SetTrueScore(score int)
 	SetAgentContributionAuditResult(agentID uuid.UUID, result bool)
 	SetAgentWithdrawalAuditResult(agentID uuid.UUID, result bool)
+	// notifications from the server while it rolls the dice for the agent
 	DecideStick()
 	DecideRollAgain()
+	HandleRollResult(roll gameRecorder.RollRecord)
+	// lifecycle notifications from the server, no-ops by default
+	OnTurnStart(iteration int, turn int)
+	OnTurnEnd(results TurnResults)
+	OnThresholdApplied(result ThresholdResult)
+	OnTeammateDied(agentID uuid.UUID, score int)
+	OnRevived()
+	OnIterationEnd(iteration int)
+	// called at the start of every iteration, the agent forgets what the policy says it forgets
+	ResetForIteration(policy MemoryPolicy)
 
 	// Strategic decisions (functions that each team can implement their own)
 	// NOTE: Any function calling these should have a parameter of type IExtendedAgent (instance IExtendedAgent)
 	DecideTeamForming(agentInfoList []ExposedAgentInfo) []uuid.UUID
 	StickOrAgain(accumulatedScore int, prevRoll int) bool
 	VoteOnAgentEntry(candidateID uuid.UUID) bool
-	StickOrAgainFor(agentId uuid.UUID, accumulatedScore int, prevRoll int) int
+	// teams the agent applies to while it is an orphan, most wanted first (nil applies
+	// to the teams of its preferred AoAs)
+	GetTeamApplications() []uuid.UUID
 
 	// Messaging functions
 	HandleTeamFormationMessage(msg *TeamFormationMessage)
@@ -51,6 +64,7 @@ type IExtendedAgent interface {
 	HandleContributionMessage(msg *ContributionMessage)
 	HandleAgentOpinionRequestMessage(msg *AgentOpinionRequestMessage)
 	HandleAgentOpinionResponseMessage(msg *AgentOpinionResponseMessage)
+	HandleThresholdNotificationMessage(msg *ThresholdNotificationMessage)
 	StateContributionToTeam(instance IExtendedAgent)
 	StateWithdrawalToTeam(instance IExtendedAgent)
 
@@ -69,33 +83,45 @@ type IExtendedAgent interface {
 	GetTrueSomasTeamID() int
 	HasTeam() bool
 
-	// Team4 AoA Functions
+	// Data Recording
+	RecordAgentStatus(instance IExtendedAgent) gameRecorder.AgentRecord
+}
+
+// Optional agent capabilities used by the AoAs that need them, detected with type assertions
+
+// IRankBoundaryAgent takes part in Team1's rank boundary ballots and chair rankings
+type IRankBoundaryAgent interface {
+	Team1_ChairUpdateRanks(rankMap map[uuid.UUID]int) map[uuid.UUID]int
+	Team1_AgreeRankBoundaries() [5]int
+	Team1_BoundaryProposalRequestHandler(msg *Team1RankBoundaryRequestMessage)
+	Team1_BoundaryProposalResponseHandler(msg *Team1RankBoundaryResponseMessage)
+	Team1_BoundaryBallotRequestHandler(msg *Team1BoundaryBallotRequestMessage)
+	Team1_BoundaryBallotResponseHandler(msg *Team1BoundaryBallotResponseMessage)
+}
+
+// ILeaderElectionAgent votes for a team leader and, once leader, rolls for the punished
+// members of its team (see ILeaderElectionAoA)
+type ILeaderElectionAgent interface {
+	Team2_GetLeaderVote() Vote
+	StickOrAgainFor(agentId uuid.UUID, accumulatedScore int, prevRoll int) int
+}
+
+// IRankedWithdrawalAgent votes on ranks and withdrawal proposals (see IRankedWithdrawalAoA)
+type IRankedWithdrawalAgent interface {
 	Team4_GetRankUpVote() map[uuid.UUID]int
-	Team4_GetConfession() bool
 	Team4_GetProposedWithdrawalVote() map[uuid.UUID]int
-
 	Team4_GetProposedWithdrawal(instance IExtendedAgent) int
 	Team4_ProposeWithdrawal() int
-
 	Team4_StateProposalToTeam()
 	Team4_CreateProposedWithdrawalMessage(statedAmount int) *Team4_ProposedWithdrawalMessage
 	Team4_HandleProposedWithdrawalMessage(msg *Team4_ProposedWithdrawalMessage)
+}
 
+// IPunishmentVoteAgent confesses when audited and votes on punishments (see IPunishmentVoteAoA)
+type IPunishmentVoteAgent interface {
+	Team4_GetConfession() bool
 	Team4_StateConfessionToTeam()
 	Team4_CreateConfessionMessage(confession bool) *Team4_ConfessionMessage
 	Team4_HandleConfessionMessage(msg *Team4_ConfessionMessage)
 	Team4_GetPunishmentVoteMap() map[int]int
-	// Data Recording
-	RecordAgentStatus(instance IExtendedAgent) gameRecorder.AgentRecord
-
-	// Team 1AoA specific functions
-	Team1_ChairUpdateRanks(rankMap map[uuid.UUID]int) map[uuid.UUID]int
-	Team1_AgreeRankBoundaries() [5]int
-	Team1_BoundaryProposalRequestHandler(msg *Team1RankBoundaryRequestMessage)
-	Team1_BoundaryProposalResponseHandler(msg *Team1RankBoundaryResponseMessage)
-	Team1_BoundaryBallotRequestHandler(msg *Team1BoundaryBallotRequestMessage)
-	Team1_BoundaryBallotResponseHandler(msg *Team1BoundaryBallotResponseMessage)
-
-	// Team 2 specific functions
-	Team2_GetLeaderVote() Vote
 }
@@ -1,6 +1,7 @@
 package common
 
 import (
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
 	"github.com/MattSScott/basePlatformSOMAS/v2/pkg/agent"
 	"github.com/google/uuid"
 )
@@ -16,6 +17,7 @@ type IServer interface {
 	UpdateAndGetAgentExposedInfo() []ExposedAgentInfo
 	IsAgentDead(agentID uuid.UUID) bool
 	GetAgentKilledScore(agentID uuid.UUID) int
+	GetReputation(agentID uuid.UUID) gameRecorder.Reputation
 	StartAgentTeamForming()
 
 	GetTeam(agentID uuid.UUID) *Team
@@ -23,7 +25,52 @@ type IServer interface {
 	GetTeamIDs() []uuid.UUID
 	GetTeamCommonPool(teamID uuid.UUID) int
 
+	// the game played to generate score
+	GetResourceGame() IResourceGame
+	// a view of the server for a new agent, see IAgentServer
+	NewAgentView() IAgentServer
+	// an ID for a new agent, drawn from the server's generator
+	NewAgentID() uuid.UUID
+
+	// Recording functions
+	RecordRoll(roll gameRecorder.RollRecord)
+
 	// Debug functions
 	LogAgentStatus()
 	PrintOrphanPool()
 }
+
+// IAgentServer is the server as seen by a single agent. It only answers what the rules
+// let the agent know: team membership and deaths (which are public), its own team's AoA
+// and common pool, and the game. Every question is recorded as an InformationRequested
+// event, including those that are refused. Other agents can not be accessed directly,
+// AccessAgentByID always returns nil.
+type IAgentServer interface {
+	// messaging, used by the base agent
+	agent.IExposedServerFunctions[IExtendedAgent]
+	// bind the view to the agent it was created for, later calls are ignored
+	SetOwner(agentID uuid.UUID)
+
+	// Team forming, an agent can only add itself to a team
+	CreateAndInitTeamWithAgents(agentIDs []uuid.UUID) uuid.UUID
+	AddAgentToTeam(agentID uuid.UUID, teamID uuid.UUID)
+
+	// Public information
+	GetTeamIDs() []uuid.UUID
+	GetAgentsInTeam(teamID uuid.UUID) []uuid.UUID
+	CheckAgentAlreadyInTeam(agentID uuid.UUID) bool
+	GetAgentTeamID(agentID uuid.UUID) uuid.UUID
+	IsAgentDead(agentID uuid.UUID) bool
+	// teams left, times kicked, failed audits and iterations survived
+	GetReputation(agentID uuid.UUID) gameRecorder.Reputation
+	GetResourceGame() IResourceGame
+
+	// The owner's team (nil and 0 if it has none)
+	GetTeamAoA() TeamAoAView
+	GetTeamAoAID() int
+	GetTeamCommonPool() int
+	// members of the team on probation and the turns they have left
+	GetTeamProbation() map[uuid.UUID]int
+	// score of a teammate killed this iteration (0 for other agents)
+	GetAgentKilledScore(agentID uuid.UUID) int
+}
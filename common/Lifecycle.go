@@ -0,0 +1,26 @@
+package common
+
+import "github.com/google/uuid"
+
+// TurnResults is what every living agent is told at the end of a turn (OnTurnEnd). It
+// only holds what the whole population can see.
+type TurnResults struct {
+	Iteration int
+	Turn      int
+	// whether the threshold was applied at the end of this turn
+	ThresholdApplied bool
+	// agents that died during the turn, in the order they died
+	Died []uuid.UUID
+	// number of agents still alive
+	LivingAgents int
+}
+
+// ThresholdResult is what a surviving agent is told once the threshold has been applied
+// (OnThresholdApplied)
+type ThresholdResult struct {
+	Turn int
+	// score taken from the agent to pay the threshold
+	Paid int
+	// the agent's score after paying
+	Score int
+}
@@ -0,0 +1,77 @@
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// MemoryRetention is how much of what an agent learnt in an iteration it keeps for the next
+type MemoryRetention int
+
+const (
+	// agents keep everything (the default)
+	MemoryFull MemoryRetention = iota
+	// agents forget everything, every iteration starts from a blank slate
+	MemoryNone
+	// agents keep a share of what they learnt, the more recent the better
+	MemoryDecayed
+)
+
+func (retention MemoryRetention) String() string {
+	switch retention {
+	case MemoryFull:
+		return "full"
+	case MemoryNone:
+		return "none"
+	case MemoryDecayed:
+		return "decayed"
+	}
+	return "unknown"
+}
+
+// MemoryPolicy is passed to every agent's ResetForIteration at the start of each
+// iteration. Agents apply it to whatever they remember about others, with the helpers
+// below, so that runs with the same policy are comparable.
+type MemoryPolicy struct {
+	Retention MemoryRetention
+	// share kept by MemoryDecayed, between 0 and 1
+	Decay float64
+}
+
+func FullMemory() MemoryPolicy {
+	return MemoryPolicy{Retention: MemoryFull}
+}
+
+func NewMemoryPolicy(retention MemoryRetention, decay float64) (MemoryPolicy, error) {
+	if retention == MemoryDecayed && (decay < 0 || decay > 1) {
+		return MemoryPolicy{}, fmt.Errorf("decay must be between 0 and 1, got %v", decay)
+	}
+	return MemoryPolicy{Retention: retention, Decay: decay}, nil
+}
+
+// Keeps reports whether anything is kept at all
+func (p MemoryPolicy) Keeps() bool {
+	return p.Retention != MemoryNone
+}
+
+// Retain returns what is left of a value remembered as value, moving it towards initial
+// (the value an agent starts with when it knows nothing)
+func (p MemoryPolicy) Retain(value, initial float64) float64 {
+	switch p.Retention {
+	case MemoryNone:
+		return initial
+	case MemoryDecayed:
+		return initial + (value-initial)*p.Decay
+	}
+	return value
+}
+
+// RetainInt is Retain for integer values, rounded to the nearest integer
+func (p MemoryPolicy) RetainInt(value, initial int) int {
+	return int(math.Round(p.Retain(float64(value), float64(initial))))
+}
+
+// RecentCount returns how many of the most recent entries of a history of length n are kept
+func (p MemoryPolicy) RecentCount(n int) int {
+	return min(n, max(0, p.RetainInt(n, 0)))
+}
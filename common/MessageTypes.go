@@ -71,6 +71,19 @@ type Team4_ConfessionMessage struct {
 	Confession bool
 }
 
+// ThresholdNotificationMessage is sent by the server (the sender is Nil) to tell an agent
+// about the threshold, as far as the ThresholdInfoMode allows. The threshold is between
+// Min and Max, which are equal when the exact value is given.
+type ThresholdNotificationMessage struct {
+	message.BaseMessage
+	Mode ThresholdInfoMode
+	Min  int
+	Max  int
+	// the threshold was applied in Turn, otherwise it will be applied in Turn
+	Applied bool
+	Turn    int
+}
+
 func (msg *TeamFormationMessage) InvokeMessageHandler(agent IExtendedAgent) {
 	agent.HandleTeamFormationMessage(msg)
 }
@@ -95,26 +108,42 @@ func (msg *AgentOpinionResponseMessage) InvokeMessageHandler(agent IExtendedAgen
 	agent.HandleAgentOpinionResponseMessage(msg)
 }
 
+func (msg *ThresholdNotificationMessage) InvokeMessageHandler(agent IExtendedAgent) {
+	agent.HandleThresholdNotificationMessage(msg)
+}
+
 func (msg *Team1RankBoundaryRequestMessage) InvokeMessageHandler(agent IExtendedAgent) {
-	agent.Team1_BoundaryProposalRequestHandler(msg)
+	if agent, ok := agent.(IRankBoundaryAgent); ok {
+		agent.Team1_BoundaryProposalRequestHandler(msg)
+	}
 }
 
 func (msg *Team1RankBoundaryResponseMessage) InvokeMessageHandler(agent IExtendedAgent) {
-	agent.Team1_BoundaryProposalResponseHandler(msg)
+	if agent, ok := agent.(IRankBoundaryAgent); ok {
+		agent.Team1_BoundaryProposalResponseHandler(msg)
+	}
 }
 
 func (msg *Team1BoundaryBallotRequestMessage) InvokeMessageHandler(agent IExtendedAgent) {
-	agent.Team1_BoundaryBallotRequestHandler(msg)
+	if agent, ok := agent.(IRankBoundaryAgent); ok {
+		agent.Team1_BoundaryBallotRequestHandler(msg)
+	}
 }
 
 func (msg *Team1BoundaryBallotResponseMessage) InvokeMessageHandler(agent IExtendedAgent) {
-	agent.Team1_BoundaryBallotResponseHandler(msg)
+	if agent, ok := agent.(IRankBoundaryAgent); ok {
+		agent.Team1_BoundaryBallotResponseHandler(msg)
+	}
 }
 
 func (msg *Team4_ProposedWithdrawalMessage) InvokeMessageHandler(agent IExtendedAgent) {
-	agent.Team4_HandleProposedWithdrawalMessage(msg)
+	if agent, ok := agent.(IRankedWithdrawalAgent); ok {
+		agent.Team4_HandleProposedWithdrawalMessage(msg)
+	}
 }
 
 func (msg *Team4_ConfessionMessage) InvokeMessageHandler(agent IExtendedAgent) {
-	agent.Team4_HandleConfessionMessage(msg)
+	if agent, ok := agent.(IPunishmentVoteAgent); ok {
+		agent.Team4_HandleConfessionMessage(msg)
+	}
 }
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// NewRandFromID derives a random number generator from a UUID. Agents and teams
+// whose IDs were drawn from the server's seeded generator therefore get their own
+// reproducible random streams.
+func NewRandFromID(id uuid.UUID) *rand.Rand {
+	seed := binary.BigEndian.Uint64(id[:8]) ^ binary.BigEndian.Uint64(id[8:])
+	return rand.New(rand.NewSource(int64(seed)))
+}
+
+// SortUUIDs sorts the IDs in place in a fixed order. Maps keyed by UUID should be
+// iterated in this order wherever the order can affect the outcome of a run.
+func SortUUIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+}
+
+// SortedKeys returns the keys of a map keyed by UUID, sorted with SortUUIDs
+func SortedKeys[V any](m map[uuid.UUID]V) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	SortUUIDs(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package common
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// IResourceGame is the game agents play every turn to generate score. An agent rolls
+// until it sticks or goes bust, in which case it loses everything rolled that turn.
+// The server owns the game, agents reach it through IServer.GetResourceGame.
+type IResourceGame interface {
+	// Roll draws the value of one roll
+	Roll(rng *rand.Rand) int
+	// IsBust reports whether roll loses the turn score (prevRoll is -1 on the first roll of a turn)
+	IsBust(roll int, prevRoll int) bool
+	// BustProbability is the chance that the roll after prevRoll goes bust
+	BustProbability(prevRoll int) float64
+	// ExpectedGain is the expected change of the turn score from rolling once more
+	ExpectedGain(accumulatedScore int, prevRoll int) float64
+	// MaxRoll is the highest value a roll can take
+	MaxRoll() int
+}
+
+// ascendingGame implements the rules shared by the games below: a roll must be higher
+// than the previous one, and probs[v] is the chance of rolling v
+type ascendingGame struct {
+	probs []float64
+}
+
+func (g ascendingGame) IsBust(roll int, prevRoll int) bool {
+	return roll <= prevRoll
+}
+
+func (g ascendingGame) BustProbability(prevRoll int) float64 {
+	pBust := 0.0
+	for value := 0; value <= prevRoll && value < len(g.probs); value++ {
+		pBust += g.probs[value]
+	}
+	return pBust
+}
+
+func (g ascendingGame) ExpectedGain(accumulatedScore int, prevRoll int) float64 {
+	eGain := 0.0
+	for value := max(prevRoll+1, 0); value < len(g.probs); value++ {
+		eGain += g.probs[value] * float64(value)
+	}
+	return eGain - g.BustProbability(prevRoll)*float64(accumulatedScore)
+}
+
+func (g ascendingGame) MaxRoll() int {
+	return len(g.probs) - 1
+}
+
+// DiceGame sums Dice dice with Faces faces each. DefaultResourceGame is 3d6.
+type DiceGame struct {
+	ascendingGame
+	Dice  int
+	Faces int
+}
+
+func NewDiceGame(dice int, faces int) (*DiceGame, error) {
+	if dice <= 0 || faces <= 0 {
+		return nil, fmt.Errorf("a dice game needs a positive number of dice and faces, got %dd%d", dice, faces)
+	}
+	// distribution of the sum, adding one die at a time
+	probs := []float64{1}
+	for i := 0; i < dice; i++ {
+		next := make([]float64, len(probs)+faces)
+		for sum, p := range probs {
+			for face := 1; face <= faces; face++ {
+				next[sum+face] += p / float64(faces)
+			}
+		}
+		probs = next
+	}
+	return &DiceGame{ascendingGame: ascendingGame{probs: probs}, Dice: dice, Faces: faces}, nil
+}
+
+func (g *DiceGame) Roll(rng *rand.Rand) int {
+	total := 0
+	for i := 0; i < g.Dice; i++ {
+		total += rng.Intn(g.Faces) + 1
+	}
+	return total
+}
+
+// The 3d6 game the simulation was designed around
+func DefaultResourceGame() IResourceGame {
+	game, _ := NewDiceGame(3, 6)
+	return game
+}
+
+// DroughtGame is a dice game in which each roll yields nothing with probability
+// DroughtChance. A drought busts every roll but the first of a turn.
+type DroughtGame struct {
+	ascendingGame
+	dice          *DiceGame
+	DroughtChance float64
+}
+
+func NewDroughtGame(dice int, faces int, droughtChance float64) (*DroughtGame, error) {
+	if droughtChance < 0 || droughtChance > 1 {
+		return nil, fmt.Errorf("droughtChance must be between 0 and 1, got %v", droughtChance)
+	}
+	diceGame, err := NewDiceGame(dice, faces)
+	if err != nil {
+		return nil, err
+	}
+	probs := make([]float64, len(diceGame.probs))
+	for value, p := range diceGame.probs {
+		probs[value] = p * (1 - droughtChance)
+	}
+	probs[0] += droughtChance
+	return &DroughtGame{ascendingGame: ascendingGame{probs: probs}, dice: diceGame, DroughtChance: droughtChance}, nil
+}
+
+func (g *DroughtGame) Roll(rng *rand.Rand) int {
+	if rng.Float64() < g.DroughtChance {
+		return 0
+	}
+	return g.dice.Roll(rng)
+}
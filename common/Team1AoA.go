@@ -6,6 +6,7 @@ import (
 	"log"
 	"math/rand"
 	"sort"
+	"strconv"
 
 	// "github.com/ADimoska/SOMASExtended/agents"
 	// "github.com/MattSScott/basePlatformSOMAS/v2/pkg/server"
@@ -18,6 +19,7 @@ type Team1AoA struct {
 	rankBoundary     [5]int
 	agentLQueue      map[uuid.UUID]*LeakyQueue
 	commonPoolWeight float64
+	rng              *rand.Rand
 }
 
 // LeakyQueue represents a queue with a fixed capacity.
@@ -196,7 +198,7 @@ func (t *Team1AoA) WeightedRandomSelection(agentIds []uuid.UUID) uuid.UUID {
 		log.Fatal("All agents have 0 weight")
 	}
 
-	randomNumber := rand.Intn(totalWeight) + 1
+	randomNumber := t.rng.Intn(totalWeight) + 1
 	cumulativeWeight := 0
 	for _, agentId := range agentIds {
 		cumulativeWeight += t.ranking[agentId]
@@ -250,13 +252,8 @@ func (t *Team1AoA) SelectNChairs(agentIds []uuid.UUID, n int) []uuid.UUID {
 * the system to 'self-organise' itself and decide on institutionalised facts
  */
 func (t *Team1AoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {
-	// Extract keys from map
-	agentIDs := make([]uuid.UUID, len(agentMap))
-	i := 0
-	for k := range agentMap {
-		agentIDs[i] = k
-		i++
-	}
+	// Extract keys from map (in a fixed order so that chair selection is reproducible)
+	agentIDs := SortedKeys(agentMap)
 
 	var chair1res [5]int // result of first randomly-elected chair
 	var chair2res [5]int // result of second randomly-elected chair
@@ -272,11 +269,12 @@ func (t *Team1AoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IE
 		// Ask both chairs to conduct a vote on what the rankings should be.
 		// This will be a collective decision conducted in two steps, see
 		// Team1AoA_ExtendedAgent.go for more details.
-		chair1res = agentMap[chair1].Team1_AgreeRankBoundaries()
-		chair2res = agentMap[chair2].Team1_AgreeRankBoundaries()
+		var ok1, ok2 bool
+		chair1res, ok1 = agreeRankBoundaries(agentMap[chair1])
+		chair2res, ok2 = agreeRankBoundaries(agentMap[chair2])
 
 		// Punish BOTH chairs if the results do not match
-		if chair1res != chair2res {
+		if !ok1 || !ok2 || chair1res != chair2res {
 			// Decrement ranking down to a minimum of 1
 			if t.ranking[chair1] > 1 {
 				t.ranking[chair1]--
@@ -299,6 +297,14 @@ func (t *Team1AoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IE
 	}
 }
 
+// Chairs that cannot take part in the ballot count as disagreeing
+func agreeRankBoundaries(chair IExtendedAgent) ([5]int, bool) {
+	if chair, ok := chair.(IRankBoundaryAgent); ok {
+		return chair.Team1_AgreeRankBoundaries(), true
+	}
+	return [5]int{}, false
+}
+
 func (t *Team1AoA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {
 	// Choose 2 chairs based on rank
 	// call function for agents to vote on ranks
@@ -313,7 +319,10 @@ func (t *Team1AoA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUI
 
 		chairs := t.SelectNChairs(team.Agents, 2)
 		for _, chairId := range chairs {
-			chair := agentMap[chairId]
+			chair, ok := agentMap[chairId].(IRankBoundaryAgent)
+			if !ok {
+				continue
+			}
 			current = chair.Team1_ChairUpdateRanks(t.ranking)
 			if prev != nil {
 				if !mapsEqual(prev, current) {
@@ -379,15 +388,19 @@ func (t *Team1AoA) GetAgentNewRank(agentId uuid.UUID) int {
 	return newRank // or an appropriate default value or error code
 }
 
-func (f *Team1AoA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int {
-	return make(map[uuid.UUID]int)
+func (t *Team1AoA) GetRanks() map[uuid.UUID]string {
+	ranks := make(map[uuid.UUID]string)
+	for agentID, rank := range t.ranking {
+		ranks[agentID] = strconv.Itoa(rank)
+	}
+	return ranks
 }
 
 func (t *Team1AoA) GetPunishment(agentScore int, agentId uuid.UUID) int {
 	return (agentScore * 25) / 100
 }
 
-func CreateTeam1AoA(team *Team) IArticlesOfAssociation {
+func CreateTeam1AoA(team *Team, rng *rand.Rand) IArticlesOfAssociation {
 	auditResult := make(map[uuid.UUID]*list.List)
 	ranking := make(map[uuid.UUID]int)
 	agentLQueue := make(map[uuid.UUID]*LeakyQueue)
@@ -403,15 +416,6 @@ func CreateTeam1AoA(team *Team) IArticlesOfAssociation {
 		rankBoundary:     [5]int{10, 20, 30, 40, 50},
 		agentLQueue:      agentLQueue,
 		commonPoolWeight: 5,
+		rng:              rng,
 	}
 }
-
-// Do nothing
-func (t *Team1AoA) Team4_SetRankUp(map[uuid.UUID]map[uuid.UUID]int) {
-}
-
-func (t *Team1AoA) Team4_RunProposedWithdrawalVote(map[uuid.UUID]int, map[uuid.UUID]map[uuid.UUID]int) {
-}
-func (t *Team1AoA) Team4_HandlePunishmentVote(map[uuid.UUID]map[int]int) int {
-	return 0
-}
@@ -28,6 +28,7 @@ type Team2AoA struct {
 	RollsLeftMap map[uuid.UUID]int
 	Leader       uuid.UUID
 	Team         *Team
+	rng          *rand.Rand
 }
 
 func (t *Team2AoA) GetExpectedContribution(agentId uuid.UUID, agentScore int) int {
@@ -160,11 +161,17 @@ func (t *Team2AoA) GetVoteResult(votes []Vote) uuid.UUID {
 		t.auditRecord.SetAuditDuration(duration)
 	}
 
-	for votedFor, votes := range voteMap {
-		if votes >= ((count / 2) + 1) {
-			return votedFor
+	// votes for the leader count double, so more than one agent can have a majority: the one with
+	// the most votes is audited, ties go to the lowest ID
+	mostVoted, mostVotes := uuid.Nil, 0
+	for _, votedFor := range SortedKeys(voteMap) {
+		if voteMap[votedFor] > mostVotes {
+			mostVoted, mostVotes = votedFor, voteMap[votedFor]
 		}
 	}
+	if mostVotes >= ((count / 2) + 1) {
+		return mostVoted
+	}
 
 	return uuid.Nil
 }
@@ -175,7 +182,7 @@ func (t *Team2AoA) GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID {
 	copy(shuffledAgents, agentIDs)
 
 	// Shuffle the agent list
-	rand.Shuffle(len(shuffledAgents), func(i, j int) {
+	t.rng.Shuffle(len(shuffledAgents), func(i, j int) {
 		shuffledAgents[i], shuffledAgents[j] = shuffledAgents[j], shuffledAgents[i]
 	})
 
@@ -194,13 +201,6 @@ func (t *Team2AoA) GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID {
 	return withdrawalOrder
 }
 
-func (t *Team2AoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent)     {}
-func (t *Team2AoA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {}
-
-func (f *Team2AoA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int {
-	return make(map[uuid.UUID]int)
-}
-
 func (t *Team2AoA) SetLeader(leader uuid.UUID) {
 	t.Leader = leader
 }
@@ -229,7 +229,7 @@ func (t *Team2AoA) GetPunishment(agentScore int, agentId uuid.UUID) int {
 	return (agentScore * multiplier) / 100
 }
 
-func CreateTeam2AoA(team *Team, leader uuid.UUID, auditDuration int) IArticlesOfAssociation {
+func CreateTeam2AoA(team *Team, leader uuid.UUID, auditDuration int, rng *rand.Rand) IArticlesOfAssociation {
 	log.Println("Creating Team2AoA")
 	offenceMap := make(map[uuid.UUID]int)
 	rollsLeftMap := make(map[uuid.UUID]int)
@@ -237,7 +237,7 @@ func CreateTeam2AoA(team *Team, leader uuid.UUID, auditDuration int) IArticlesOf
 	if leader == uuid.Nil {
 		shuffledAgents := make([]uuid.UUID, len(team.Agents))
 		copy(shuffledAgents, team.Agents)
-		rand.Shuffle(len(shuffledAgents), func(i, j int) {
+		rng.Shuffle(len(shuffledAgents), func(i, j int) {
 			shuffledAgents[i], shuffledAgents[j] = shuffledAgents[j], shuffledAgents[i]
 		})
 		leader = shuffledAgents[0]
@@ -249,14 +249,58 @@ func CreateTeam2AoA(team *Team, leader uuid.UUID, auditDuration int) IArticlesOf
 		RollsLeftMap: rollsLeftMap,
 		Leader:       leader,
 		Team:         team,
+		rng:          rng,
 	}
 }
 
-// Do nothing
-func (t *Team2AoA) Team4_SetRankUp(rankUpVoteMap map[uuid.UUID]map[uuid.UUID]int) {
+// ---------------------------------------- Admission ----------------------------------------
+
+// The leader can veto any orphan the majority would let in
+func (t *Team2AoA) DecideAdmission(orphanID uuid.UUID, orphanScore int, votes map[uuid.UUID]bool, majority float32) AdmissionDecision {
+	if leaderVote, ok := votes[t.Leader]; ok && !leaderVote {
+		return AdmissionDecision{}
+	}
+	return AdmissionDecision{Admitted: MajorityAdmission(votes, majority)}
 }
-func (t *Team2AoA) Team4_RunProposedWithdrawalVote(map[uuid.UUID]int, map[uuid.UUID]map[uuid.UUID]int) {
+
+// ---------------------------------------- Turn Phases ----------------------------------------
+
+// Punished agents have their rolls made by the leader, a caught leader is re-elected and
+// agents are kicked on their third offence
+func (t *Team2AoA) OverrideTurnPhase(phase TurnPhase) (TurnPhaseFunc, bool) {
+	switch phase {
+	case RollPhase:
+		return t.roll, true
+	case PunishmentPhase:
+		return t.punish, true
+	}
+	return nil, false
 }
-func (t *Team2AoA) Team4_HandlePunishmentVote(map[uuid.UUID]map[int]int) int {
-	return 0
+
+func (t *Team2AoA) roll(server ITurnServer, state *TurnState) {
+	for _, agentID := range state.Team.Agents {
+		if !IsActiveAgent(server, agentID) {
+			continue
+		}
+		if t.GetRollsLeft(agentID) > 0 {
+			t.RollOnce(agentID)
+			server.OverrideAgentRolls(agentID, t.GetLeader())
+		} else {
+			server.RollDice(agentID)
+		}
+	}
+}
+
+func (t *Team2AoA) punish(server ITurnServer, state *TurnState) {
+	server.RunDefaultTurnPhase(PunishmentPhase, state)
+	if state.AuditedAgent == uuid.Nil || !state.AuditResult {
+		return
+	}
+
+	if state.AuditedAgent == t.GetLeader() {
+		server.ElectNewLeader(state.Team.TeamID)
+	}
+	if t.GetOffences(state.AuditedAgent) == 3 {
+		server.RemoveAgentFromTeam(state.AuditedAgent)
+	}
 }
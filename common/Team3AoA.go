@@ -1 +1,201 @@
 package common
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Team 3 AoA: a progressive contribution tax, audits over a short window paid for from the
+// common pool, equal shares of the common pool handed out to the most generous contributors
+// first, and fines that grow with every offence.
+
+// ---------------------------------------- Articles of Association Functionality ----------------------------------------
+
+// Contribution brackets: nothing is expected on the first team3TaxFreeScore points of an
+// agent's score, team3LowRate percent up to team3HighBracket and team3HighRate percent above it
+const (
+	team3TaxFreeScore = 5
+	team3HighBracket  = 20
+	team3LowRate      = 20
+	team3HighRate     = 40
+
+	// number of turns an audit looks back over
+	team3AuditWindow = 3
+	// share of the score taken per offence, in percent
+	team3FinePerOffence = 20
+)
+
+type Team3AoA struct {
+	auditRecord *AuditRecord
+	// total contributed by each agent this iteration, decides the withdrawal order
+	contributions map[uuid.UUID]int
+	// number of times each agent has been caught
+	offences map[uuid.UUID]int
+	// the pool when the withdrawal phase started, shared equally during it
+	withdrawalPool int
+	withdrawing    bool
+	team           *Team
+}
+
+// Progressive tax on the agent's score
+func (t *Team3AoA) GetExpectedContribution(agentId uuid.UUID, agentScore int) int {
+	expected := 0
+	if agentScore > team3TaxFreeScore {
+		expected += (min(agentScore, team3HighBracket) - team3TaxFreeScore) * team3LowRate / 100
+	}
+	if agentScore > team3HighBracket {
+		expected += (agentScore - team3HighBracket) * team3HighRate / 100
+	}
+	return expected
+}
+
+// Contributing less than stated, or less than the tax, is an infraction
+func (t *Team3AoA) SetContributionAuditResult(agentId uuid.UUID, agentScore int, agentActualContribution int, agentStatedContribution int) {
+	infraction := 0
+	if agentActualContribution < agentStatedContribution || agentActualContribution < t.GetExpectedContribution(agentId, agentScore) {
+		infraction = 1
+	}
+	t.auditRecord.AddRecord(agentId, infraction)
+	t.contributions[agentId] += agentActualContribution
+}
+
+// true means the agent committed an infraction within the audit window
+func (t *Team3AoA) GetContributionAuditResult(agentId uuid.UUID) bool {
+	return t.audit(agentId)
+}
+
+// Every member is entitled to an equal share of the pool. During the withdrawal phase the
+// share is that of the pool before anyone withdrew, so the last to withdraw get as much
+// as the first.
+func (t *Team3AoA) GetExpectedWithdrawal(agentId uuid.UUID, agentScore int, commonPool int) int {
+	if len(t.team.Agents) == 0 {
+		return 0
+	}
+	if t.withdrawing {
+		commonPool = t.withdrawalPool
+	}
+	return commonPool / len(t.team.Agents)
+}
+
+// Withdrawing more than stated, or more than an equal share, is an infraction
+func (t *Team3AoA) SetWithdrawalAuditResult(agentId uuid.UUID, agentScore int, agentActualWithdrawal int, agentStatedWithdrawal int, commonPool int) {
+	if agentActualWithdrawal > agentStatedWithdrawal || agentActualWithdrawal > t.GetExpectedWithdrawal(agentId, agentScore, commonPool) {
+		// a contribution audit this turn cleared the record the infraction would be added to
+		if len(t.auditRecord.GetAuditMap()[agentId]) == 0 {
+			t.auditRecord.AddRecord(agentId, 1)
+		} else {
+			t.auditRecord.IncrementLastRecord(agentId)
+		}
+	}
+}
+
+func (t *Team3AoA) GetWithdrawalAuditResult(agentId uuid.UUID) bool {
+	return t.audit(agentId)
+}
+
+// Infractions are cleared once audited so they are only punished once
+func (t *Team3AoA) audit(agentId uuid.UUID) bool {
+	infractions := t.auditRecord.GetAllInfractions(agentId) > 0
+	t.auditRecord.ClearAllInfractions(agentId)
+	return infractions
+}
+
+// Audits get more expensive as the pool grows, with a minimum cost of 1 (see RunPaidAudit)
+func (t *Team3AoA) GetAuditCost(commonPool int) int {
+	return max(1, commonPool/10)
+}
+
+// An agent is audited only if a strict majority of the votes name the same agent
+func (t *Team3AoA) GetVoteResult(votes []Vote) uuid.UUID {
+	if len(votes) == 0 {
+		return uuid.Nil
+	}
+
+	voteCount := make(map[uuid.UUID]int)
+	for _, vote := range votes {
+		if vote.IsVote == 1 && vote.VotedForID != uuid.Nil {
+			voteCount[vote.VotedForID]++
+		}
+	}
+
+	for _, agentID := range SortedKeys(voteCount) {
+		if voteCount[agentID] > len(votes)/2 {
+			return agentID
+		}
+	}
+	return uuid.Nil
+}
+
+// The agents who contributed the most this iteration withdraw first
+func (t *Team3AoA) GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID {
+	withdrawalOrder := make([]uuid.UUID, len(agentIDs))
+	copy(withdrawalOrder, agentIDs)
+	SortUUIDs(withdrawalOrder)
+	sort.SliceStable(withdrawalOrder, func(i, j int) bool {
+		return t.contributions[withdrawalOrder[i]] > t.contributions[withdrawalOrder[j]]
+	})
+	return withdrawalOrder
+}
+
+// Each offence, counted by the punishment phase, adds team3FinePerOffence percent of the
+// agent's score to the fine
+func (t *Team3AoA) GetPunishment(agentScore int, agentId uuid.UUID) int {
+	percentage := min(t.offences[agentId]*team3FinePerOffence, 100)
+	return agentScore * percentage / 100
+}
+
+func (t *Team3AoA) GetOffences(agentId uuid.UUID) int {
+	return t.offences[agentId]
+}
+
+// An orphan the majority lets in pays one turn of tax on its score into the pool
+func (t *Team3AoA) DecideAdmission(orphanID uuid.UUID, orphanScore int, votes map[uuid.UUID]bool, majority float32) AdmissionDecision {
+	if !MajorityAdmission(votes, majority) {
+		return AdmissionDecision{}
+	}
+	return AdmissionDecision{Admitted: true, EntryFee: t.GetExpectedContribution(orphanID, orphanScore)}
+}
+
+// ---------------------------------------- Turn Phases ----------------------------------------
+
+// Audits are paid for from the common pool, and every agent caught counts an offence before
+// it is punished
+func (t *Team3AoA) OverrideTurnPhase(phase TurnPhase) (TurnPhaseFunc, bool) {
+	switch phase {
+	case ContributionAuditPhase, WithdrawalAuditPhase:
+		return RunPaidAudit, true
+	case PunishmentPhase:
+		return t.punish, true
+	}
+	return nil, false
+}
+
+// Fix the equal share of the pool for the withdrawal phase
+func (t *Team3AoA) BeforeTurnPhase(phase TurnPhase, server ITurnServer, state *TurnState) {
+	if phase == WithdrawPhase {
+		t.withdrawalPool, t.withdrawing = state.Team.GetCommonPool(), true
+	}
+}
+
+func (t *Team3AoA) AfterTurnPhase(phase TurnPhase, server ITurnServer, state *TurnState) {
+	if phase == WithdrawPhase {
+		t.withdrawing = false
+	}
+}
+
+func (t *Team3AoA) punish(server ITurnServer, state *TurnState) {
+	if state.AuditedAgent != uuid.Nil && state.AuditResult {
+		t.offences[state.AuditedAgent]++
+	}
+	server.RunDefaultTurnPhase(PunishmentPhase, state)
+}
+
+func CreateTeam3AoA(team *Team) IArticlesOfAssociation {
+	return &Team3AoA{
+		auditRecord:   NewAuditRecord(team3AuditWindow),
+		contributions: make(map[uuid.UUID]int),
+		offences:      make(map[uuid.UUID]int),
+		team:          team,
+	}
+}
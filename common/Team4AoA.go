@@ -39,6 +39,8 @@ type Team4AoA struct {
 		ExpectedWithdrawal int
 	}
 	AuditMap map[uuid.UUID][]int
+	// decided by the punishment vote of this turn's withdrawal audit, collected in the punishment phase
+	fine int
 }
 
 func (t *Team4AoA) GetExpectedContribution(agentId uuid.UUID, agentScore int) int {
@@ -75,10 +77,17 @@ func (t *Team4AoA) Team4_HandlePunishmentVote(punishmentVoteMap map[uuid.UUID]ma
 		medianGrades[punishment] = getMedian(grades)
 	}
 
-	// Determine punishment with the highest median grade
+	// Determine punishment with the highest median grade (ties go to the lightest punishment)
+	punishments := make([]int, 0, len(medianGrades))
+	for punishment := range medianGrades {
+		punishments = append(punishments, punishment)
+	}
+	sort.Ints(punishments)
+
 	var selectedPunishment int
 	highestMedian := -1
-	for punishment, median := range medianGrades {
+	for _, punishment := range punishments {
+		median := medianGrades[punishment]
 		if median > highestMedian {
 			highestMedian = median
 			selectedPunishment = punishment
@@ -150,6 +159,14 @@ func (t *Team4AoA) Team4_SetRankUp(rankUpVoteMap map[uuid.UUID]map[uuid.UUID]int
 	}
 }
 
+func (t *Team4AoA) GetRanks() map[uuid.UUID]string {
+	ranks := make(map[uuid.UUID]string)
+	for agentID, adventurer := range t.Adventurers {
+		ranks[agentID] = adventurer.Rank
+	}
+	return ranks
+}
+
 func (t *Team4AoA) RankUp(agentID uuid.UUID) {
 	adventurer, exists := t.Adventurers[agentID]
 	if !exists {
@@ -316,8 +333,8 @@ func (t *Team4AoA) GetVoteResult(votes []Vote) uuid.UUID {
 	threshold := t.GetVoteThreshold()
 
 	// Check if any candidate's exceed the threshold
-	for votedForID, totalVotes := range voteMap {
-		if totalVotes >= threshold {
+	for _, votedForID := range SortedKeys(voteMap) {
+		if voteMap[votedForID] >= threshold {
 			return votedForID
 		}
 	}
@@ -380,20 +397,99 @@ func (t *Team4AoA) GetVoteWeight(rank string) int {
 	}
 }
 
-// Unused Functions
+func (t *Team4AoA) GetPunishment(agentScore int, agentId uuid.UUID) int {
+	return (agentScore * 25) / 100
+}
+
+// ---------------------------------------- Admission ----------------------------------------
+
+// Votes on orphans are weighted by the voter's rank, members without a rank vote as rank F
+func (t *Team4AoA) DecideAdmission(orphanID uuid.UUID, orphanScore int, votes map[uuid.UUID]bool, majority float32) AdmissionDecision {
+	yesWeight, totalWeight := 0, 0
+	for voterID, vote := range votes {
+		rank := "F"
+		if adventurer, exists := t.Adventurers[voterID]; exists {
+			rank = adventurer.Rank
+		}
+		weight := t.GetVoteWeight(rank)
+		totalWeight += weight
+		if vote {
+			yesWeight += weight
+		}
+	}
+	if totalWeight == 0 || float32(yesWeight)/float32(totalWeight) < majority {
+		return AdmissionDecision{}
+	}
+	return AdmissionDecision{Admitted: true}
+}
 
-func (t *Team4AoA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {
+// An admitted orphan starts at rank F
+func (t *Team4AoA) OnMemberAdmitted(agentID uuid.UUID) {
+	t.Adventurers[agentID] = struct {
+		Rank               string
+		ExpectedWithdrawal int
+	}{
+		Rank:               "F",
+		ExpectedWithdrawal: 1,
+	}
+	t.AuditMap[agentID] = []int{}
+}
 
+// ---------------------------------------- Turn Phases ----------------------------------------
+
+// An agent chosen for a withdrawal audit confesses and its fine is put to a punishment vote, whatever
+// the audit finds, before the team hears the result. Rank-ups and withdrawal proposals are run by the
+// server (IRankedWithdrawalAoA).
+func (t *Team4AoA) OverrideTurnPhase(phase TurnPhase) (TurnPhaseFunc, bool) {
+	switch phase {
+	case WithdrawalAuditPhase:
+		return t.auditWithdrawal, true
+	case PunishmentPhase:
+		return t.collectFine, true
+	}
+	return nil, false
 }
 
-func (f *Team4AoA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int {
-	return make(map[uuid.UUID]int)
+// contribution audits only inform the team; withdrawal audits lead to a confession and a punishment vote
+func (t *Team4AoA) auditWithdrawal(server ITurnServer, state *TurnState) {
+	t.fine = 0
+	state.AuditedAgent = server.AgentToAudit(state)
+	if state.AuditedAgent != uuid.Nil {
+		t.runPunishmentVote(server, state)
+	}
+	server.RunDefaultTurnPhase(WithdrawalAuditPhase, state)
 }
 
-func (t *Team4AoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {
+func (t *Team4AoA) runPunishmentVote(server ITurnServer, state *TurnState) {
+	agent := server.GetAgentMap()[state.AuditedAgent]
+	if confessor, ok := agent.(IPunishmentVoteAgent); ok {
+		confessor.Team4_StateConfessionToTeam()
+	}
+	agentScore := agent.GetTrueScore()
+	punishmentVoteMap := make(map[uuid.UUID]map[int]int)
+	for _, agentID := range state.Team.Agents {
+		if voter, ok := server.GetAgentMap()[agentID].(IPunishmentVoteAgent); ok {
+			punishmentVoteMap[agentID] = voter.Team4_GetPunishmentVoteMap()
+		}
+	}
 
+	t.fine = t.Team4_HandlePunishmentVote(punishmentVoteMap) * agentScore / 100
+	log.Printf("Punishment Result for Agent %v: %d (Agent Score: %d)\n", agent.GetID(), t.fine, agentScore)
 }
 
-func (t *Team4AoA) GetPunishment(agentScore int, agentId uuid.UUID) int {
-	return (agentScore * 25) / 100
+// the fine moves from the agent's score to the common pool
+func (t *Team4AoA) collectFine(server ITurnServer, state *TurnState) {
+	fine := t.fine
+	t.fine = 0
+	if state.AuditPhase != WithdrawalAuditPhase || state.AuditedAgent == uuid.Nil {
+		return
+	}
+
+	agent := server.GetAgentMap()[state.AuditedAgent]
+	agent.SetTrueScore(agent.GetTrueScore() - fine)
+	log.Printf("Updated Score for Agent %v: %d\n", agent.GetID(), agent.GetTrueScore())
+
+	log.Printf("Current Common Pool: %d\n", state.Team.GetCommonPool())
+	state.Team.SetCommonPool(state.Team.GetCommonPool() + fine)
+	log.Printf("Updated Common Pool: %d\n", state.Team.GetCommonPool())
 }
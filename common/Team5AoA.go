@@ -4,7 +4,6 @@ import (
 	// environmentServer "SOMAS_Extended/server"
 	"container/list"
 	"math/rand"
-	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,6 +13,7 @@ type Team5AOA struct {
 	WithdrawalAuditMap   map[uuid.UUID]bool
 	ContributionRoundMap map[uuid.UUID]int // Tracks the number of successful contribution rounds for each agent
 	Allocation           map[uuid.UUID]int // Stores the resource allocation for each agent
+	rng                  *rand.Rand
 }
 
 // ResetAuditMap resets the audit maps for both contribution and withdrawal
@@ -125,15 +125,12 @@ func (f *Team5AOA) GetVoteResult(votes []Vote) uuid.UUID {
 
 // GetWithdrawalOrder returns a shuffled order of agents for withdrawal
 func (t *Team5AOA) GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID {
-	// Seed the random number generator to ensure different shuffles each time
-	rand.Seed(time.Now().UnixNano())
-
 	// Create a copy of the agentIDs to avoid modifying the original list
 	shuffledAgents := make([]uuid.UUID, len(agentIDs))
 	copy(shuffledAgents, agentIDs)
 
 	// Shuffle the agent list
-	rand.Shuffle(len(shuffledAgents), func(i, j int) {
+	t.rng.Shuffle(len(shuffledAgents), func(i, j int) {
 		shuffledAgents[i], shuffledAgents[j] = shuffledAgents[j], shuffledAgents[i]
 	})
 
@@ -174,9 +171,6 @@ func (f *Team5AOA) KickOutAgent(agentId uuid.UUID) bool {
 	return false
 }
 
-func (t *Team5AOA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent)     {}
-func (t *Team5AOA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {}
-
 func (f *Team5AOA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int {
 	// Step 1: Calculate the need threshold (T)
 	var scores []int
@@ -189,10 +183,7 @@ func (f *Team5AOA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingRe
 	threshold := max(medianScore, int(float64(meanScore)*alpha))
 
 	// Step 2: Allocate resources based on need level until needs are met or resources are depleted
-	agentIDs := make([]uuid.UUID, 0, len(agentScores))
-	for agentID := range agentScores {
-		agentIDs = append(agentIDs, agentID)
-	}
+	agentIDs := SortedKeys(agentScores)
 
 	// Sort agent IDs based on scores in ascending order (lower scores get higher priority)
 	sortedAgents := make([]uuid.UUID, len(agentIDs))
@@ -279,26 +270,60 @@ func min(a, b int) int {
 	return b
 }
 
-// CreateFixedAoA creates a new instance of Team5AOA
-func CreateTeam5AoA() IArticlesOfAssociation {
+// CreateTeam5AoA creates a new instance of Team5AOA
+func CreateTeam5AoA(rng *rand.Rand) IArticlesOfAssociation {
 	return &Team5AOA{
 		ContributionAuditMap: make(map[uuid.UUID]*list.List),
 		WithdrawalAuditMap:   make(map[uuid.UUID]bool),
 		ContributionRoundMap: make(map[uuid.UUID]int),
 		Allocation:           make(map[uuid.UUID]int),
+		rng:                  rng,
 	}
 }
 
-// Do nothing
-func (t *Team5AOA) Team4_SetRankUp(map[uuid.UUID]map[uuid.UUID]int) {
+func (t *Team5AOA) GetPunishment(agentScore int, agentId uuid.UUID) int {
+	return (agentScore * 25) / 100
 }
 
-func (t *Team5AOA) Team4_RunProposedWithdrawalVote(map[uuid.UUID]int, map[uuid.UUID]map[uuid.UUID]int) {
-}
-func (t *Team5AOA) Team4_HandlePunishmentVote(map[uuid.UUID]map[int]int) int {
-	return 0
+// ---------------------------------------- Turn Phases ----------------------------------------
+
+// Contributions are measured against the expected contribution rather than a statement, audits
+// are paid for from the common pool, nobody is punished and withdrawals are not stated to the
+// team. The server allocates the pool by need before withdrawals (IResourceAllocationAoA).
+func (t *Team5AOA) OverrideTurnPhase(phase TurnPhase) (TurnPhaseFunc, bool) {
+	switch phase {
+	case ContributePhase:
+		return t.contribute, true
+	case ContributionAuditPhase, WithdrawalAuditPhase:
+		return RunPaidAudit, true
+	case PunishmentPhase, StateWithdrawalPhase:
+		return func(ITurnServer, *TurnState) {}, true
+	}
+	return nil, false
 }
 
-func (t *Team5AOA) GetPunishment(agentScore int, agentId uuid.UUID) int {
-	return (agentScore * 25) / 100
+func (t *Team5AOA) contribute(server ITurnServer, state *TurnState) {
+	team := state.Team
+	// Sum of contributions from all agents in the team for this turn
+	agentContributionsTotal := 0
+	for _, agentID := range team.Agents {
+		if !IsActiveAgent(server, agentID) {
+			continue
+		}
+		agent := server.GetAgentMap()[agentID]
+		agentScore := agent.GetTrueScore()
+		expectedContribution := t.GetExpectedContribution(agentID, agentScore)
+
+		// Agents make actual contribution
+		agentActualContribution := agent.GetActualContribution(agent)
+
+		// Update audit result
+		t.SetContributionAuditResult(agentID, agentScore, agentActualContribution, expectedContribution)
+		agent.SetTrueScore(agentScore - agentActualContribution)
+		state.RecordContribution(agentID, agentActualContribution, expectedContribution)
+		agentContributionsTotal += agentActualContribution
+	}
+
+	// Update common pool with total contribution from this team
+	team.SetCommonPool(team.GetCommonPool() + agentContributionsTotal)
 }
@@ -1,10 +1,10 @@
 package common
 
 import (
-	"time"
+	"math/rand"
 
 	"github.com/google/uuid"
-	"golang.org/x/exp/rand"
+	exprand "golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/stat/distuv"
 )
 
@@ -23,9 +23,10 @@ type Team6AoA struct {
 	auditHistory            map[uuid.UUID][]*CheatingRecord // Audit history per agent
 	agentsToMonitor         map[uuid.UUID]int64             // Monitoring tracking
 
+	rng *rand.Rand
 }
 
-func CreateTeam6AoA() IArticlesOfAssociation {
+func CreateTeam6AoA(rng *rand.Rand) IArticlesOfAssociation {
 	return &Team6AoA{
 		weight: float64(0.4), // Weight for current turn contributions
 		decay:  float64(0.9), // Decay rate for cumulative contributions
@@ -34,6 +35,7 @@ func CreateTeam6AoA() IArticlesOfAssociation {
 		currentContributions:    make(map[uuid.UUID]float64),           // Current turn contributions
 		auditHistory:            make(map[uuid.UUID][]*CheatingRecord), // Audit history per agent
 		agentsToMonitor:         make(map[uuid.UUID]int64),
+		rng:                     rng,
 	}
 }
 
@@ -163,7 +165,8 @@ func (t *Team6AoA) GetVoteResult(votes []Vote) uuid.UUID {
 	var maxVotedAgent uuid.UUID
 	maxVotes := 0.0
 
-	for agentID, voteTotal := range voteTotals {
+	for _, agentID := range SortedKeys(voteTotals) {
+		voteTotal := voteTotals[agentID]
 		if voteTotal > maxVotes && voteTotal > auditThreshold {
 			maxVotedAgent = agentID
 			maxVotes = voteTotal
@@ -184,9 +187,10 @@ func (t *Team6AoA) RunContributionMonitoring() {
 
 	// for now, we're saying monitoring is included in cost of audit
 
-	for monitAgent, monitStage := range t.agentsToMonitor {
+	for _, monitAgent := range SortedKeys(t.agentsToMonitor) {
+		monitStage := t.agentsToMonitor[monitAgent]
 
-		source := rand.NewSource(uint64(time.Now().UnixNano()))
+		source := exprand.NewSource(t.rng.Uint64())
 
 		// Check if agent has any audit history
 		if monitHistory, monitExists := t.auditHistory[monitAgent]; monitExists && len(monitHistory) > 0 {
@@ -201,8 +205,8 @@ func (t *Team6AoA) RunContributionMonitoring() {
 					// stage 1, half of actual
 					lambda := float64((lastMonitRecord.Actual + lastMonitRecord.Expected) / 2)
 					poisson := distuv.Poisson{
-						Lambda: lambda,           // The rate parameter
-						Src:    rand.New(source), // Random source
+						Lambda: lambda,              // The rate parameter
+						Src:    exprand.New(source), // Random source
 					}
 					monitCheck := poisson.Rand()
 
@@ -221,8 +225,8 @@ func (t *Team6AoA) RunContributionMonitoring() {
 
 					lambda := float64((halfwayActualExp + lastMonitRecord.Actual) / 2)
 					poisson := distuv.Poisson{
-						Lambda: lambda,           // The rate parameter
-						Src:    rand.New(source), // Random source
+						Lambda: lambda,              // The rate parameter
+						Src:    exprand.New(source), // Random source
 					}
 					monitCheck := poisson.Rand()
 
@@ -238,8 +242,8 @@ func (t *Team6AoA) RunContributionMonitoring() {
 					// stage 3, full actual
 					lambda := float64(lastMonitRecord.Actual)
 					poisson := distuv.Poisson{
-						Lambda: lambda,           // The rate parameter
-						Src:    rand.New(source), // Random source
+						Lambda: lambda,              // The rate parameter
+						Src:    exprand.New(source), // Random source
 					}
 					monitCheck := poisson.Rand()
 
@@ -306,9 +310,10 @@ func (t *Team6AoA) RunWithdrawalMonitoring() {
 	// for now, we're saying monitoring is
 	// included as part of audit cost
 
-	for monitAgent, monitStage := range t.agentsToMonitor {
+	for _, monitAgent := range SortedKeys(t.agentsToMonitor) {
+		monitStage := t.agentsToMonitor[monitAgent]
 
-		source := rand.NewSource(uint64(time.Now().UnixNano()))
+		source := exprand.NewSource(t.rng.Uint64())
 
 		// Check if agent has any audit history
 		if monitHistory, monitExists := t.auditHistory[monitAgent]; monitExists && len(monitHistory) > 0 {
@@ -323,8 +328,8 @@ func (t *Team6AoA) RunWithdrawalMonitoring() {
 					// stage 1, half of actual
 					lambda := float64(lastMonitRecord.Actual / 2)
 					poisson := distuv.Poisson{
-						Lambda: lambda,           // The rate parameter
-						Src:    rand.New(source), // Random source
+						Lambda: lambda,              // The rate parameter
+						Src:    exprand.New(source), // Random source
 					}
 					monitCheck := poisson.Rand()
 
@@ -340,8 +345,8 @@ func (t *Team6AoA) RunWithdrawalMonitoring() {
 					// stage 2, 3/4 of actual
 					lambda := float64(3 * lastMonitRecord.Actual / 4)
 					poisson := distuv.Poisson{
-						Lambda: lambda,           // The rate parameter
-						Src:    rand.New(source), // Random source
+						Lambda: lambda,              // The rate parameter
+						Src:    exprand.New(source), // Random source
 					}
 					monitCheck := poisson.Rand()
 
@@ -357,8 +362,8 @@ func (t *Team6AoA) RunWithdrawalMonitoring() {
 					// stage 3, full actual
 					lambda := float64(lastMonitRecord.Actual)
 					poisson := distuv.Poisson{
-						Lambda: lambda,           // The rate parameter
-						Src:    rand.New(source), // Random source
+						Lambda: lambda,              // The rate parameter
+						Src:    exprand.New(source), // Random source
 					}
 					monitCheck := poisson.Rand()
 
@@ -420,7 +425,8 @@ func (t *Team6AoA) CalculateVotingPower() map[uuid.UUID]float64 {
 
 	// Calculate weighted contributions for each agent using the formula:
 	// weighted_contribution = (w × current_contribution) + ((1-w) × cumulative_contribution)
-	for agentID, current := range t.currentContributions {
+	for _, agentID := range SortedKeys(t.currentContributions) {
+		current := t.currentContributions[agentID]
 		cumulative := t.cumulativeContributions[agentID]
 		// Apply weighting of current vs. cumulative
 		weighted := (t.weight * current) + ((1 - t.weight) * cumulative)
@@ -478,42 +484,14 @@ func (t *Team6AoA) GetPunishment(agentScore int, agentID uuid.UUID) int { // Pun
 }
 
 func (t *Team6AoA) GetWithdrawalOrder(agentIDs []uuid.UUID) []uuid.UUID {
-	// math.rand.Seed(time.Now().UnixNano())
 	// Create a copy of the agentIDs to avoid modifying the original list
 	shuffledAgents := make([]uuid.UUID, len(agentIDs))
 	copy(shuffledAgents, agentIDs)
 
 	// Shuffle the agent list
-	rand.Shuffle(len(shuffledAgents), func(i, j int) {
+	t.rng.Shuffle(len(shuffledAgents), func(i, j int) {
 		shuffledAgents[i], shuffledAgents[j] = shuffledAgents[j], shuffledAgents[i]
 	})
 
 	return shuffledAgents
 }
-
-// --------------------------------------------------------------------------------------------------------------- //
-
-// not needed, dw abt it, here to fix error complaints
-func (f *Team6AoA) ResourceAllocation(agentScores map[uuid.UUID]int, remainingResources int) map[uuid.UUID]int {
-	return make(map[uuid.UUID]int)
-}
-
-// not needed, dw abt it, here to fix error complaints
-func (t *Team6AoA) RunPreIterationAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {}
-
-// not needed, dw abt it, here to fix error complaints
-func (t *Team6AoA) Team4_HandlePunishmentVote(map[uuid.UUID]map[int]int) int {
-	return 0
-}
-
-// not needed, dw abt it, here to fix error complaints
-func (t *Team6AoA) Team4_RunProposedWithdrawalVote(map[uuid.UUID]int, map[uuid.UUID]map[uuid.UUID]int) {
-}
-
-// not needed, dw abt it, here to fix error complaints
-func (t *Team6AoA) Team4_SetRankUp(rankUpVoteMap map[uuid.UUID]map[uuid.UUID]int) {
-}
-
-// not needed, dw abt it, here to fix error complaints
-func (t *Team6AoA) RunPostContributionAoaLogic(team *Team, agentMap map[uuid.UUID]IExtendedAgent) {
-}
@@ -0,0 +1,141 @@
+package common
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// ThresholdInfoMode is what the server tells agents about the threshold, through a
+// ThresholdNotificationMessage
+type ThresholdInfoMode int
+
+const (
+	// agents are told nothing
+	ThresholdInfoNone ThresholdInfoMode = iota
+	// agents are told each threshold when it is chosen
+	ThresholdInfoExact
+	// agents are told a range containing each threshold when it is chosen
+	ThresholdInfoRange
+	// agents are told each threshold once it has been applied
+	ThresholdInfoAfterApplied
+)
+
+func (mode ThresholdInfoMode) String() string {
+	switch mode {
+	case ThresholdInfoNone:
+		return "none"
+	case ThresholdInfoExact:
+		return "exact"
+	case ThresholdInfoRange:
+		return "range"
+	case ThresholdInfoAfterApplied:
+		return "afterApplied"
+	}
+	return "unknown"
+}
+
+// ThresholdState is what a threshold policy can base the next threshold on
+type ThresholdState struct {
+	Turn      int
+	Iteration int
+	// the threshold set before this one, if any was set yet
+	Previous    int
+	HasPrevious bool
+	// scores of the living agents
+	Scores []int
+}
+
+// IThresholdPolicy sets the score every agent must reach each time the threshold is
+// applied (every thresholdTurns turns). Agents below it die and the others pay it.
+type IThresholdPolicy interface {
+	// Name identifies the policy in the records
+	Name() string
+	// Parameters of the policy, for the records
+	Parameters() map[string]float64
+	NextThreshold(state ThresholdState, rng *rand.Rand) int
+}
+
+// FixedThreshold is the same threshold throughout the game
+type FixedThreshold struct {
+	Value int
+}
+
+func (p FixedThreshold) Name() string { return "fixed" }
+
+func (p FixedThreshold) Parameters() map[string]float64 {
+	return map[string]float64{"value": float64(p.Value)}
+}
+
+func (p FixedThreshold) NextThreshold(state ThresholdState, rng *rand.Rand) int {
+	return p.Value
+}
+
+// LinearThreshold grows with the turn: Base + Growth*turn, plus a random amount below
+// Spread. DefaultThresholdPolicy (0, 1, 10) is the threshold the game was designed with.
+type LinearThreshold struct {
+	Base   int
+	Growth float64
+	Spread int
+}
+
+func DefaultThresholdPolicy() IThresholdPolicy {
+	return LinearThreshold{Base: 0, Growth: 1, Spread: 10}
+}
+
+func (p LinearThreshold) Name() string { return "linear" }
+
+func (p LinearThreshold) Parameters() map[string]float64 {
+	return map[string]float64{"base": float64(p.Base), "growth": p.Growth, "spread": float64(p.Spread)}
+}
+
+func (p LinearThreshold) NextThreshold(state ThresholdState, rng *rand.Rand) int {
+	threshold := p.Base + int(p.Growth*float64(state.Turn))
+	if p.Spread > 0 {
+		threshold += rng.Intn(p.Spread)
+	}
+	return threshold
+}
+
+// RandomWalkThreshold starts at Start and then moves by at most Step either way, never below 0
+type RandomWalkThreshold struct {
+	Start int
+	Step  int
+}
+
+func (p RandomWalkThreshold) Name() string { return "randomWalk" }
+
+func (p RandomWalkThreshold) Parameters() map[string]float64 {
+	return map[string]float64{"start": float64(p.Start), "step": float64(p.Step)}
+}
+
+func (p RandomWalkThreshold) NextThreshold(state ThresholdState, rng *rand.Rand) int {
+	if !state.HasPrevious {
+		return p.Start
+	}
+	return max(0, state.Previous+rng.Intn(2*p.Step+1)-p.Step)
+}
+
+// MedianThreshold is a share of the median score of the living agents, so the pressure
+// follows how well the population is doing
+type MedianThreshold struct {
+	Fraction float64
+}
+
+func (p MedianThreshold) Name() string { return "median" }
+
+func (p MedianThreshold) Parameters() map[string]float64 {
+	return map[string]float64{"fraction": p.Fraction}
+}
+
+func (p MedianThreshold) NextThreshold(state ThresholdState, rng *rand.Rand) int {
+	if len(state.Scores) == 0 {
+		return 0
+	}
+	scores := slices.Clone(state.Scores)
+	slices.Sort(scores)
+	median := float64(scores[len(scores)/2])
+	if len(scores)%2 == 0 {
+		median = float64(scores[len(scores)/2-1]+scores[len(scores)/2]) / 2
+	}
+	return max(0, int(p.Fraction*median))
+}
@@ -0,0 +1,188 @@
+package common
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// TurnPhase names one step of a team's turn. The server runs the phases of TurnPhases in
+// order for every team; an AoA changes what happens in a phase by overriding it
+// (ITurnPhaseOverrides) or by running extra logic around it (IBeforeTurnPhaseHook,
+// IAfterTurnPhaseHook), instead of copying the whole turn into the server.
+type TurnPhase int
+
+const (
+	// agents roll the dice to gain resources
+	RollPhase TurnPhase = iota
+	// agents contribute to and state their contribution to the common pool
+	ContributePhase
+	// agents vote on whom to audit for their contribution
+	ContributionAuditVotePhase
+	// the agent voted for (if any) is audited and the team is told the result
+	ContributionAuditPhase
+	// agents propose or vote on withdrawals before withdrawing (no-op by default)
+	WithdrawalProposalPhase
+	// agents withdraw from the common pool in the AoA's withdrawal order
+	WithdrawPhase
+	// agents state their withdrawal to the team, in a random order
+	StateWithdrawalPhase
+	// agents vote on whom to audit for their withdrawal
+	WithdrawalAuditVotePhase
+	// the agent voted for (if any) is audited and the team is told the result
+	WithdrawalAuditPhase
+	// the agent caught by the preceding audit is punished
+	PunishmentPhase
+)
+
+// TurnPhases is the order in which the phases of a turn are run. The punishment phase
+// follows each of the two audits; TurnState.AuditPhase tells which one.
+var TurnPhases = []TurnPhase{
+	RollPhase,
+	ContributePhase,
+	ContributionAuditVotePhase,
+	ContributionAuditPhase,
+	PunishmentPhase,
+	WithdrawalProposalPhase,
+	WithdrawPhase,
+	StateWithdrawalPhase,
+	WithdrawalAuditVotePhase,
+	WithdrawalAuditPhase,
+	PunishmentPhase,
+}
+
+var turnPhaseNames = map[TurnPhase]string{
+	RollPhase:                  "Roll",
+	ContributePhase:            "Contribute",
+	ContributionAuditVotePhase: "ContributionAuditVote",
+	ContributionAuditPhase:     "ContributionAudit",
+	WithdrawalProposalPhase:    "WithdrawalProposal",
+	WithdrawPhase:              "Withdraw",
+	StateWithdrawalPhase:       "StateWithdrawal",
+	WithdrawalAuditVotePhase:   "WithdrawalAuditVote",
+	WithdrawalAuditPhase:       "WithdrawalAudit",
+	PunishmentPhase:            "Punishment",
+}
+
+func (phase TurnPhase) String() string {
+	if name, ok := turnPhaseNames[phase]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// TurnState is shared by the phases of one team's turn
+type TurnState struct {
+	Team *Team
+
+	ContributionAuditVotes []Vote
+	WithdrawalAuditVotes   []Vote
+
+	// the audit phase that ran last (ContributionAuditPhase or WithdrawalAuditPhase)
+	AuditPhase TurnPhase
+	// the agent chosen by that audit's vote (uuid.Nil if nobody was audited). An override may set it
+	// before running the default audit phase, which then audits that agent instead of counting the votes again.
+	AuditedAgent uuid.UUID
+	// whether the audited agent was caught
+	AuditResult bool
+	// taken from the common pool to run the audit, for AoAs that charge for audits
+	AuditCost int
+
+	// flows through the common pool so far this turn, kept by the server
+	Contributed          int
+	Withdrawn            int
+	AuditCostPaid        int
+	PunishmentsCollected int
+
+	// what each agent actually gave and took, and what it stated, as it was applied
+	Contributions map[uuid.UUID]Transaction
+	Withdrawals   map[uuid.UUID]Transaction
+}
+
+// Transaction is an agent's contribution to or withdrawal from the common pool
+type Transaction struct {
+	Actual int
+	Stated int
+}
+
+// RecordContribution must be called by every phase that moves an agent's contribution into the pool
+func (state *TurnState) RecordContribution(agentID uuid.UUID, actual int, stated int) {
+	if state.Contributions == nil {
+		state.Contributions = make(map[uuid.UUID]Transaction)
+	}
+	state.Contributions[agentID] = Transaction{Actual: actual, Stated: stated}
+}
+
+// RecordWithdrawal must be called by every phase that moves an agent's withdrawal out of the pool
+func (state *TurnState) RecordWithdrawal(agentID uuid.UUID, actual int, stated int) {
+	if state.Withdrawals == nil {
+		state.Withdrawals = make(map[uuid.UUID]Transaction)
+	}
+	state.Withdrawals[agentID] = Transaction{Actual: actual, Stated: stated}
+}
+
+// ITurnServer is the part of the server available to turn phases
+type ITurnServer interface {
+	IServer
+	GetAgentMap() map[uuid.UUID]IExtendedAgent
+	GetAgentScores() map[uuid.UUID]int
+	ApplyPunishment(team *Team, agentID uuid.UUID)
+	RemoveAgentFromTeam(agentID uuid.UUID)
+	ElectNewLeader(teamID uuid.UUID)
+	RollDice(agentID uuid.UUID)
+	OverrideAgentRolls(agentID uuid.UUID, leaderID uuid.UUID)
+	// a new random stream derived from the server's seed
+	NewRand() *rand.Rand
+
+	// runs the server's default implementation of the phase, for overrides that extend it
+	RunDefaultTurnPhase(phase TurnPhase, state *TurnState)
+	// the agent the current audit phase (state.AuditPhase) will audit, uuid.Nil if nobody
+	AgentToAudit(state *TurnState) uuid.UUID
+}
+
+// TurnPhaseFunc implements a phase of the turn
+type TurnPhaseFunc func(server ITurnServer, state *TurnState)
+
+// ITurnPhaseOverrides is implemented by AoAs that replace some phases of the turn
+type ITurnPhaseOverrides interface {
+	// returns the implementation of the phase, or false to use the server's default
+	OverrideTurnPhase(phase TurnPhase) (TurnPhaseFunc, bool)
+}
+
+// IBeforeTurnPhaseHook is implemented by AoAs that run logic before every phase
+type IBeforeTurnPhaseHook interface {
+	BeforeTurnPhase(phase TurnPhase, server ITurnServer, state *TurnState)
+}
+
+// IAfterTurnPhaseHook is implemented by AoAs that run logic after every phase
+type IAfterTurnPhaseHook interface {
+	AfterTurnPhase(phase TurnPhase, server ITurnServer, state *TurnState)
+}
+
+// IsActiveAgent reports whether the agent can take part in the turn: it exists, is alive and is in a team
+func IsActiveAgent(server ITurnServer, agentID uuid.UUID) bool {
+	agent := server.GetAgentMap()[agentID]
+	return agent != nil && agent.GetTeamID() != uuid.Nil && !server.IsAgentDead(agentID)
+}
+
+// RunPaidAudit runs the default audit phase for AoAs that charge for audits: an audit only goes
+// ahead if the common pool can pay the AoA's audit cost, which is taken from the pool
+func RunPaidAudit(server ITurnServer, state *TurnState) {
+	agentToAudit := server.AgentToAudit(state)
+	if agentToAudit == uuid.Nil {
+		return
+	}
+
+	auditCost := state.Team.TeamAoA.GetAuditCost(state.Team.GetCommonPool())
+	if auditCost > state.Team.GetCommonPool() {
+		log.Printf("[server] Not enough resources in the common pool to cover the audit cost. Skipping %v.\n", state.AuditPhase)
+		return
+	}
+	// Deduct the audit cost from the common pool
+	state.Team.SetCommonPool(state.Team.GetCommonPool() - auditCost)
+	state.AuditCost, state.AuditedAgent = auditCost, agentToAudit
+	log.Printf("[server] Audit cost of %v deducted from the common pool. Remaining pool: %v\n", auditCost, state.Team.GetCommonPool())
+
+	server.RunDefaultTurnPhase(state.AuditPhase, state)
+}
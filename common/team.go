@@ -14,6 +14,8 @@ type Team struct {
 	TeamAoA    IArticlesOfAssociation
 	TeamAoAID  int
 	commonPool int
+	// turns each member on probation has left to serve
+	probation map[uuid.UUID]int
 }
 
 func (team *Team) GetCommonPool() int {
@@ -31,11 +33,59 @@ func (team *Team) RemoveAgent(agentID uuid.UUID) {
 			break
 		}
 	}
+	delete(team.probation, agentID)
+}
+
+// --------- Probation ---------
+// New members can be put on probation for a number of turns, during which they may withdraw
+// less from the common pool and are audited when the team does not vote for an audit.
+
+// Put the member on probation for the given number of turns (0 ends it)
+func (team *Team) StartProbation(agentID uuid.UUID, turns int) {
+	if turns <= 0 {
+		delete(team.probation, agentID)
+		return
+	}
+	if team.probation == nil {
+		team.probation = make(map[uuid.UUID]int)
+	}
+	team.probation[agentID] = turns
+}
+
+// Turns the member has left on probation, 0 if it is a full member
+func (team *Team) GetProbation(agentID uuid.UUID) int {
+	return team.probation[agentID]
+}
+
+func (team *Team) IsOnProbation(agentID uuid.UUID) bool {
+	return team.probation[agentID] > 0
+}
+
+// The members on probation and the turns they have left
+func (team *Team) GetProbationers() map[uuid.UUID]int {
+	probationers := make(map[uuid.UUID]int, len(team.probation))
+	for agentID, turns := range team.probation {
+		probationers[agentID] = turns
+	}
+	return probationers
+}
+
+// Count a turn served by every member on probation, and return those who became full members, in order
+func (team *Team) ServeProbation() []uuid.UUID {
+	served := []uuid.UUID{}
+	for _, agentID := range SortedKeys(team.probation) {
+		team.probation[agentID]--
+		if team.probation[agentID] <= 0 {
+			delete(team.probation, agentID)
+			served = append(served, agentID)
+		}
+	}
+	return served
 }
 
 // constructor: NewTeam creates a new Team with a unique TeamID and initializes other fields as blank.
 func NewTeam(teamID uuid.UUID) *Team {
-	teamAoA := CreateFixedAoA(1)
+	teamAoA := CreateFixedAoA(1, NewRandFromID(teamID))
 	return &Team{
 		TeamID:     teamID,        // Generate a unique TeamID
 		commonPool: 0,             // Initialize commonPool to 0
@@ -45,6 +95,20 @@ func NewTeam(teamID uuid.UUID) *Team {
 }
 
 // --------- Recording Functions ---------
+// Record what the team knows about itself: its AoA, members, pool, and leader or ranks if the AoA has them
 func (team *Team) RecordTeamStatus() gameRecorder.TeamRecord {
-	return gameRecorder.NewTeamRecord(team.TeamID)
+	record := gameRecorder.NewTeamRecord(team.TeamID)
+	record.TeamAoAID = team.TeamAoAID
+	record.TeamCommonPool = team.GetCommonPool()
+	record.Agents = append([]uuid.UUID{}, team.Agents...)
+	if aoa, ok := team.TeamAoA.(ILeaderElectionAoA); ok {
+		record.Leader = aoa.GetLeader()
+	}
+	if aoa, ok := team.TeamAoA.(IRankedAoA); ok {
+		record.Ranks = aoa.GetRanks()
+	}
+	if len(team.probation) > 0 {
+		record.Probation = team.GetProbationers()
+	}
+	return record
 }
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	baseServer "github.com/MattSScott/basePlatformSOMAS/v2/pkg/server"
+
+	agents "github.com/ADimoska/SOMASExtended/agents"
+	common "github.com/ADimoska/SOMASExtended/common"
+	envServer "github.com/ADimoska/SOMASExtended/server"
+)
+
+// AgentConstructor creates one agent described by a population entry
+type AgentConstructor func(serv *envServer.EnvironmentServer, agentConfig agents.AgentConfig, entry PopulationEntry) common.IExtendedAgent
+
+// AgentConstructors maps the constructor names usable in a scenario file to the agent constructors.
+// Teams can add their own agents here.
+var AgentConstructors = map[string]AgentConstructor{
+	"Base": func(serv *envServer.EnvironmentServer, agentConfig agents.AgentConfig, _ PopulationEntry) common.IExtendedAgent {
+		return agents.GetBaseAgents(serv, agentConfig)
+	},
+	"Team1": func(serv *envServer.EnvironmentServer, agentConfig agents.AgentConfig, entry PopulationEntry) common.IExtendedAgent {
+		agentType := agentTypes[entry.AgentType]
+		agent := agents.Create_Team1Agent(serv, agentConfig, agentType)
+		if agentType != agents.Honest {
+			log.Printf("Team1 %v is of type %s", agent.GetID(), entry.AgentType)
+		}
+		return agent
+	},
+	"Team2": func(serv *envServer.EnvironmentServer, agentConfig agents.AgentConfig, _ PopulationEntry) common.IExtendedAgent {
+		return agents.Team2_CreateAgent(serv, agentConfig)
+	},
+	"Team4": func(serv *envServer.EnvironmentServer, agentConfig agents.AgentConfig, _ PopulationEntry) common.IExtendedAgent {
+		return agents.Team4_CreateAgent(serv, agentConfig)
+	},
+	"Bandit": func(serv *envServer.EnvironmentServer, agentConfig agents.AgentConfig, entry PopulationEntry) common.IExtendedAgent {
+		agent := agents.CreateAoABanditAgent(serv, agentConfig)
+		if entry.Exploration > 0 {
+			agent.Exploration = entry.Exploration
+		}
+		return agent
+	},
+}
+
+// constructors that use PopulationEntry.AgentType
+var typedAgents = map[string]bool{
+	"Team1": true,
+}
+
+// names accepted for PopulationEntry.AgentType (an empty name means Honest)
+var agentTypes = map[string]agents.AgentType{
+	"":               agents.Honest,
+	"Honest":         agents.Honest,
+	"CheatLongTerm":  agents.CheatLongTerm,
+	"CheatShortTerm": agents.CheatShortTerm,
+}
+
+// NewServer creates and initialises an EnvironmentServer from the server section of the config
+func NewServer(cfg *SimulationConfig) *envServer.EnvironmentServer {
+	serv := &envServer.EnvironmentServer{
+		// note: the zero turn is used for team forming
+		BaseServer: baseServer.CreateBaseServer[common.IExtendedAgent](
+			cfg.Server.Iterations,
+			cfg.Server.Turns,
+			cfg.Server.MaxDuration,
+			cfg.Server.MessageBandwidth),
+		Teams: make(map[uuid.UUID]*common.Team),
+	}
+	serv.Init(cfg.Server.ThresholdTurns)
+	serv.SetGameRunner(serv)
+
+	seed := cfg.Server.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	serv.SetSeed(seed)
+	serv.SetMajorityVoteThreshold(cfg.Server.MajorityVoteThreshold)
+	serv.SetMaxOrphanRejections(cfg.Server.MaxOrphanRejections)
+	serv.SetForcedAoA(cfg.Server.ForcedAoA)
+	serv.SetTeamFormingDelay(cfg.Server.TeamFormingDelay)
+	// validated by BuildSimulation
+	game, err := cfg.Server.Game.NewResourceGame()
+	if err != nil {
+		log.Printf("[config] %v, using the default game\n", err)
+		game = common.DefaultResourceGame()
+	}
+	serv.SetResourceGame(game)
+	policy, err := cfg.Server.Threshold.NewThresholdPolicy()
+	if err != nil {
+		log.Printf("[config] %v, using the default threshold policy\n", err)
+		policy = common.DefaultThresholdPolicy()
+	}
+	serv.SetThresholdPolicy(policy)
+	serv.SetThresholdHidden(cfg.Server.Threshold.Hidden)
+	serv.SetThresholdInfo(thresholdInfoModes[cfg.Server.Threshold.Info], cfg.Server.Threshold.InfoRange)
+	memory, err := cfg.Server.Memory.NewMemoryPolicy()
+	if err != nil {
+		log.Printf("[config] %v, agents keep their whole memory\n", err)
+	}
+	serv.SetMemoryPolicy(memory)
+	serv.SetProbation(cfg.Server.Probation.Turns, cfg.Server.Probation.WithdrawalShare)
+	return serv
+}
+
+// NewPopulation creates the agents described by the population section, in order.
+// Agent IDs are drawn from the server's seeded generator.
+func NewPopulation(serv *envServer.EnvironmentServer, cfg *SimulationConfig) []common.IExtendedAgent {
+	agentPopulation := []common.IExtendedAgent{}
+	for _, entry := range cfg.Population {
+		agentConfig := agents.AgentConfig{
+			InitScore:    entry.InitScore,
+			VerboseLevel: entry.VerboseLevel,
+		}
+		for i := 0; i < entry.Count; i++ {
+			agent := AgentConstructors[entry.Agent](serv, agentConfig, entry)
+			if len(entry.AoARanking) > 0 {
+				agent.SetAoARanking(append([]int{}, entry.AoARanking...))
+			}
+			agentPopulation = append(agentPopulation, agent)
+		}
+	}
+	return agentPopulation
+}
+
+// BuildSimulation validates the config and returns a server with the whole population added, ready to Start
+func BuildSimulation(cfg *SimulationConfig) (*envServer.EnvironmentServer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	serv := NewServer(cfg)
+	for i, agent := range NewPopulation(serv, cfg) {
+		agent.SetName(i)
+		serv.AddAgent(agent)
+	}
+	return serv, nil
+}
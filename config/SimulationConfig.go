@@ -0,0 +1,326 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"gopkg.in/yaml.v3"
+)
+
+// SimulationConfig describes a complete scenario: the server parameters and
+// the agent population that is added to the server before it starts.
+// It can be written as YAML or JSON (JSON is a subset of YAML).
+type SimulationConfig struct {
+	Server     ServerConfig      `yaml:"server"`
+	Population []PopulationEntry `yaml:"population"`
+}
+
+// ServerConfig holds the parameters passed to the base server and EnvironmentServer.Init
+type ServerConfig struct {
+	Iterations       int           `yaml:"iterations"`
+	Turns            int           `yaml:"turns"`
+	MaxDuration      time.Duration `yaml:"maxDuration"`
+	MessageBandwidth int           `yaml:"messageBandwidth"`
+	// number of turns between two applications of the score threshold
+	ThresholdTurns int `yaml:"thresholdTurns"`
+	// runs with the same seed and population produce identical records (0 picks a seed from the clock)
+	Seed int64 `yaml:"seed"`
+	// share of a team that must accept an orphan (0 uses the default of 0.7)
+	MajorityVoteThreshold float32 `yaml:"majorityVoteThreshold"`
+	// teams that may reject an orphan before it waits to be put in a new team with other
+	// such orphans (0 lets orphans apply forever)
+	MaxOrphanRejections int `yaml:"maxOrphanRejections"`
+	// AoA adopted by every team instead of voting (0 lets teams vote)
+	ForcedAoA int `yaml:"forcedAoA"`
+	// wall-clock pause between team forming and the AoA vote
+	TeamFormingDelay time.Duration `yaml:"teamFormingDelay"`
+	// game played to generate score (3d6 when omitted)
+	Game GameConfig `yaml:"game"`
+	// how the threshold is chosen (a random amount below 10, plus the turn, when omitted)
+	Threshold ThresholdConfig `yaml:"threshold"`
+	// how much agents remember from one iteration to the next (everything when omitted)
+	Memory MemoryConfig `yaml:"memory"`
+	// probation of orphans admitted into a team (none when omitted)
+	Probation ProbationConfig `yaml:"probation"`
+}
+
+// GameConfig selects the resource game, see common/ResourceGame.go
+type GameConfig struct {
+	// "dice" (the default) or "drought"
+	Type string `yaml:"type"`
+	// 0 uses 3 dice of 6 faces
+	Dice  int `yaml:"dice"`
+	Faces int `yaml:"faces"`
+	// chance that a roll of the drought game yields nothing
+	DroughtChance float64 `yaml:"droughtChance"`
+}
+
+// ThresholdConfig selects the threshold policy, see common/ThresholdPolicy.go
+type ThresholdConfig struct {
+	// "linear" (the default), "fixed", "randomWalk" or "median"
+	Policy string `yaml:"policy"`
+	// fixed: the threshold, randomWalk: the first threshold
+	Value int `yaml:"value"`
+	// linear: base + growth * turn + a random amount below spread
+	// (0, 1 and 10 when the policy is omitted)
+	Base   int     `yaml:"base"`
+	Growth float64 `yaml:"growth"`
+	Spread int     `yaml:"spread"`
+	// randomWalk: largest change between two thresholds
+	Step int `yaml:"step"`
+	// median: share of the median score of the living agents
+	Fraction float64 `yaml:"fraction"`
+	// choose each threshold when it is applied, instead of at the start of the period
+	Hidden bool `yaml:"hidden"`
+	// what agents are told: "none" (the default), "exact", "range" or "afterApplied"
+	Info string `yaml:"info"`
+	// width of the range agents are told in the range mode
+	InfoRange int `yaml:"infoRange"`
+}
+
+// MemoryConfig selects the memory policy, see common/MemoryPolicy.go
+type MemoryConfig struct {
+	// "full" (the default), "none" or "decayed"
+	Retention string `yaml:"retention"`
+	// decayed: share of what was learnt that is kept, between 0 and 1
+	Decay float64 `yaml:"decay"`
+}
+
+// ProbationConfig sets the probation of orphans admitted into a team
+type ProbationConfig struct {
+	// turns spent on probation (0 for none)
+	Turns int `yaml:"turns"`
+	// share of an equal split of the common pool a member on probation may withdraw, between 0 and 1
+	WithdrawalShare float64 `yaml:"withdrawalShare"`
+}
+
+// names accepted for MemoryConfig.Retention
+var memoryRetentions = map[string]common.MemoryRetention{
+	"":        common.MemoryFull,
+	"full":    common.MemoryFull,
+	"none":    common.MemoryNone,
+	"decayed": common.MemoryDecayed,
+}
+
+// NewMemoryPolicy creates the policy described by the config
+func (mc MemoryConfig) NewMemoryPolicy() (common.MemoryPolicy, error) {
+	retention, ok := memoryRetentions[mc.Retention]
+	if !ok {
+		return common.MemoryPolicy{}, fmt.Errorf("unknown retention %q", mc.Retention)
+	}
+	return common.NewMemoryPolicy(retention, mc.Decay)
+}
+
+// names accepted for ThresholdConfig.Info
+var thresholdInfoModes = map[string]common.ThresholdInfoMode{
+	"":             common.ThresholdInfoNone,
+	"none":         common.ThresholdInfoNone,
+	"exact":        common.ThresholdInfoExact,
+	"range":        common.ThresholdInfoRange,
+	"afterApplied": common.ThresholdInfoAfterApplied,
+}
+
+// NewThresholdPolicy creates the policy described by the config
+func (tc ThresholdConfig) NewThresholdPolicy() (common.IThresholdPolicy, error) {
+	switch tc.Policy {
+	case "":
+		return common.DefaultThresholdPolicy(), nil
+	case "linear":
+		if tc.Spread < 0 {
+			return nil, fmt.Errorf("spread must not be negative, got %d", tc.Spread)
+		}
+		return common.LinearThreshold{Base: tc.Base, Growth: tc.Growth, Spread: tc.Spread}, nil
+	case "fixed":
+		return common.FixedThreshold{Value: tc.Value}, nil
+	case "randomWalk":
+		if tc.Step < 0 {
+			return nil, fmt.Errorf("step must not be negative, got %d", tc.Step)
+		}
+		return common.RandomWalkThreshold{Start: tc.Value, Step: tc.Step}, nil
+	case "median":
+		if tc.Fraction < 0 {
+			return nil, fmt.Errorf("fraction must not be negative, got %v", tc.Fraction)
+		}
+		return common.MedianThreshold{Fraction: tc.Fraction}, nil
+	default:
+		return nil, fmt.Errorf("unknown threshold policy %q", tc.Policy)
+	}
+}
+
+// NewResourceGame creates the game described by the config
+func (gc GameConfig) NewResourceGame() (common.IResourceGame, error) {
+	dice, faces := gc.Dice, gc.Faces
+	if dice == 0 {
+		dice = 3
+	}
+	if faces == 0 {
+		faces = 6
+	}
+	switch gc.Type {
+	case "", "dice":
+		game, err := common.NewDiceGame(dice, faces)
+		if err != nil {
+			return nil, err
+		}
+		return game, nil
+	case "drought":
+		game, err := common.NewDroughtGame(dice, faces, gc.DroughtChance)
+		if err != nil {
+			return nil, err
+		}
+		return game, nil
+	default:
+		return nil, fmt.Errorf("unknown game type %q", gc.Type)
+	}
+}
+
+// PopulationEntry describes Count agents created with the same constructor and settings
+type PopulationEntry struct {
+	// name of the agent constructor, see AgentConstructors
+	Agent string `yaml:"agent"`
+	Count int    `yaml:"count"`
+	// behaviour of Team1 agents: Honest, CheatLongTerm or CheatShortTerm
+	AgentType    string `yaml:"agentType"`
+	InitScore    int    `yaml:"initScore"`
+	VerboseLevel int    `yaml:"verboseLevel"`
+	// overrides the AoA ranking chosen by the constructor when non-empty
+	AoARanking []int `yaml:"aoaRanking"`
+	// weight of exploration of Bandit agents (0 uses the UCB1 default)
+	Exploration float64 `yaml:"exploration"`
+}
+
+// DefaultConfig reproduces the scenario that used to be hard-coded in main.go
+func DefaultConfig() *SimulationConfig {
+	// Team4 and Team2 agents were created alternately, then the Team1 agents
+	population := []PopulationEntry{}
+	for i := 0; i < 10; i++ {
+		population = append(population,
+			PopulationEntry{Agent: "Team4", Count: 1, VerboseLevel: 10},
+			PopulationEntry{Agent: "Team2", Count: 1, VerboseLevel: 10})
+	}
+	population = append(population,
+		PopulationEntry{Agent: "Team1", Count: 8, AgentType: "Honest", VerboseLevel: 10},
+		PopulationEntry{Agent: "Team1", Count: 1, AgentType: "CheatShortTerm", VerboseLevel: 10},
+		PopulationEntry{Agent: "Team1", Count: 1, AgentType: "CheatLongTerm", VerboseLevel: 10})
+
+	return &SimulationConfig{
+		Server: ServerConfig{
+			Iterations:            2,
+			Turns:                 100,
+			MaxDuration:           50 * time.Millisecond,
+			MessageBandwidth:      10,
+			ThresholdTurns:        3,
+			MajorityVoteThreshold: 0.7,
+			TeamFormingDelay:      2 * time.Second,
+		},
+		Population: population,
+	}
+}
+
+// LoadConfig reads a YAML or JSON scenario file and validates it
+func LoadConfig(path string) (*SimulationConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes a YAML or JSON scenario and validates it
+func ParseConfig(data []byte) (*SimulationConfig, error) {
+	cfg := &SimulationConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that the scenario can be turned into a server and population
+func (cfg *SimulationConfig) Validate() error {
+	if cfg.Server.Iterations <= 0 {
+		return fmt.Errorf("server.iterations must be positive, got %d", cfg.Server.Iterations)
+	}
+	if cfg.Server.Turns <= 0 {
+		return fmt.Errorf("server.turns must be positive, got %d", cfg.Server.Turns)
+	}
+	if cfg.Server.MaxDuration <= 0 {
+		return fmt.Errorf("server.maxDuration must be positive, got %v", cfg.Server.MaxDuration)
+	}
+	if cfg.Server.MessageBandwidth <= 0 {
+		return fmt.Errorf("server.messageBandwidth must be positive, got %d", cfg.Server.MessageBandwidth)
+	}
+	if cfg.Server.ThresholdTurns <= 0 {
+		return fmt.Errorf("server.thresholdTurns must be positive, got %d", cfg.Server.ThresholdTurns)
+	}
+	if cfg.Server.MajorityVoteThreshold < 0 || cfg.Server.MajorityVoteThreshold > 1 {
+		return fmt.Errorf("server.majorityVoteThreshold must be between 0 and 1, got %v", cfg.Server.MajorityVoteThreshold)
+	}
+	if cfg.Server.MaxOrphanRejections < 0 {
+		return fmt.Errorf("server.maxOrphanRejections must not be negative, got %d", cfg.Server.MaxOrphanRejections)
+	}
+	if _, ok := common.GetAoARegistration(cfg.Server.ForcedAoA); cfg.Server.ForcedAoA != 0 && !ok {
+		return fmt.Errorf("server.forcedAoA must be one of the registered AoAs %v (or 0), got %d", common.RegisteredAoAIDs(), cfg.Server.ForcedAoA)
+	}
+	if cfg.Server.TeamFormingDelay < 0 {
+		return fmt.Errorf("server.teamFormingDelay must not be negative, got %v", cfg.Server.TeamFormingDelay)
+	}
+	if _, err := cfg.Server.Game.NewResourceGame(); err != nil {
+		return fmt.Errorf("server.game: %v", err)
+	}
+	if _, err := cfg.Server.Threshold.NewThresholdPolicy(); err != nil {
+		return fmt.Errorf("server.threshold: %v", err)
+	}
+	if _, ok := thresholdInfoModes[cfg.Server.Threshold.Info]; !ok {
+		return fmt.Errorf("server.threshold.info: unknown mode %q", cfg.Server.Threshold.Info)
+	}
+	if cfg.Server.Threshold.InfoRange < 0 {
+		return fmt.Errorf("server.threshold.infoRange must not be negative, got %d", cfg.Server.Threshold.InfoRange)
+	}
+	if _, err := cfg.Server.Memory.NewMemoryPolicy(); err != nil {
+		return fmt.Errorf("server.memory: %v", err)
+	}
+	if cfg.Server.Probation.Turns < 0 {
+		return fmt.Errorf("server.probation.turns must not be negative, got %d", cfg.Server.Probation.Turns)
+	}
+	if cfg.Server.Probation.WithdrawalShare < 0 || cfg.Server.Probation.WithdrawalShare > 1 {
+		return fmt.Errorf("server.probation.withdrawalShare must be between 0 and 1, got %v", cfg.Server.Probation.WithdrawalShare)
+	}
+	if len(cfg.Population) == 0 {
+		return fmt.Errorf("population must contain at least one entry")
+	}
+
+	for i, entry := range cfg.Population {
+		if _, ok := AgentConstructors[entry.Agent]; !ok {
+			return fmt.Errorf("population[%d]: unknown agent constructor %q", i, entry.Agent)
+		}
+		if entry.Count <= 0 {
+			return fmt.Errorf("population[%d]: count must be positive, got %d", i, entry.Count)
+		}
+		if entry.Exploration < 0 {
+			return fmt.Errorf("population[%d]: exploration must not be negative, got %v", i, entry.Exploration)
+		}
+		if entry.AgentType != "" {
+			if !typedAgents[entry.Agent] {
+				return fmt.Errorf("population[%d]: %s agents do not take an agentType", i, entry.Agent)
+			}
+			if _, ok := agentTypes[entry.AgentType]; !ok {
+				return fmt.Errorf("population[%d]: unknown agentType %q", i, entry.AgentType)
+			}
+		}
+	}
+	return nil
+}
+
+// NumAgents returns the total number of agents described by the population
+func (cfg *SimulationConfig) NumAgents() int {
+	total := 0
+	for _, entry := range cfg.Population {
+		total += entry.Count
+	}
+	return total
+}
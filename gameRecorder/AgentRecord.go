@@ -14,7 +14,7 @@ type AgentRecord struct {
 	AgentID         uuid.UUID
 	TrueSomasTeamID int // SOMAS team number, e.g., Team 4
 
-	// turn-specific fields
+	// turn-specific fields, the contributions and withdrawals are the ones the server applied
 	IsAlive            bool
 	Score              int
 	Contribution       int
@@ -22,26 +22,45 @@ type AgentRecord struct {
 	Withdrawal         int
 	StatedWithdrawal   int
 
+	// lie metrics: positive when the agent claimed to give more than it did
+	// (ContributionLie) or took more than it claimed (WithdrawalLie)
+	ContributionLie int
+	WithdrawalLie   int
+	// sum of the positive lies of the agent since the start of the game
+	CumulativeDishonesty int
+
+	// summary of the agent's dice rolls this turn, see RollRecord for the full sequence
+	RollRounds     int
+	RollTurnScore  int
+	Bust           bool
+	RollControlled bool // a Team2 leader made the stick decisions
+
 	TeamID uuid.UUID
 
 	// special indicator fields for agents
 	SpecialNote string
 }
 
-func NewAgentRecord(agentID uuid.UUID, trueSomasTeamID int, score int, contribution int, statedContribution int, withdrawal int, statedWithdrawal int, teamID uuid.UUID, specialNote string) AgentRecord {
+func NewAgentRecord(agentID uuid.UUID, trueSomasTeamID int, score int, teamID uuid.UUID, specialNote string) AgentRecord {
 	return AgentRecord{
-		AgentID:            agentID,
-		TrueSomasTeamID:    trueSomasTeamID,
-		Score:              score,
-		Contribution:       contribution,
-		StatedContribution: statedContribution,
-		Withdrawal:         withdrawal,
-		StatedWithdrawal:   statedWithdrawal,
-		TeamID:             teamID,
-		SpecialNote:        specialNote,
+		AgentID:         agentID,
+		TrueSomasTeamID: trueSomasTeamID,
+		Score:           score,
+		TeamID:          teamID,
+		SpecialNote:     specialNote,
 	}
 }
 
+// Set the contribution and withdrawal applied this turn and the lies told about them
+func (ar *AgentRecord) SetTransactions(contribution int, statedContribution int, withdrawal int, statedWithdrawal int) {
+	ar.Contribution = contribution
+	ar.StatedContribution = statedContribution
+	ar.Withdrawal = withdrawal
+	ar.StatedWithdrawal = statedWithdrawal
+	ar.ContributionLie = statedContribution - contribution
+	ar.WithdrawalLie = withdrawal - statedWithdrawal
+}
+
 func NewTeamRecord(teamID uuid.UUID) TeamRecord {
 	return TeamRecord{
 		TeamID: teamID,
@@ -8,8 +8,22 @@ type CommonRecord struct {
 	TurnNumber      int
 	IterationNumber int
 
-	Threshold              int  // current threshold set by server
+	Threshold              int  // current threshold set by server (the last one applied if it is hidden)
 	ThresholdAppliedInTurn bool // whether the threshold was applied in the current turn
+
+	// how the threshold is chosen, see common.IThresholdPolicy
+	ThresholdPolicy     string
+	ThresholdParameters map[string]float64
+	// chosen when applied instead of at the start of the period
+	ThresholdHidden bool
+	// turns between two applications of the threshold
+	ThresholdTurns int
+	// what agents are told about the threshold, see common.ThresholdInfoMode
+	ThresholdInfo string
+
+	// how much agents remember from one iteration to the next, see common.MemoryPolicy
+	MemoryRetention string
+	MemoryDecay     float64
 }
 
 func NewCommonRecord(turnNumber int, iterationNumber int, threshold int, thresholdAppliedInTurn bool) CommonRecord {
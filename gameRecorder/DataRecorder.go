@@ -1,8 +1,13 @@
 package gameRecorder
 
 import (
+	"encoding/json"
+	"io"
 	"log"
 	"sort"
+	"sync"
+
+	"github.com/google/uuid"
 )
 
 // --------- General External Functions ---------
@@ -16,6 +21,7 @@ type TurnRecord struct {
 	AgentRecords    []AgentRecord
 	TeamRecords     []TeamRecord
 	CommonRecord    CommonRecord
+	RollRecords     []RollRecord
 }
 
 // turn record constructor
@@ -29,9 +35,21 @@ func NewTurnRecord(turnNumber int, iterationNumber int) TurnRecord {
 // --------- Server Recording Functions ---------
 type ServerDataRecorder struct {
 	TurnRecords []TurnRecord // where all our info is stored!
+	Events      []EventRecord
 
 	currentIteration int
 	currentTurn      int
+	// index of the first event recorded since the last turn record
+	turnEventsStart int
+	// kept up to date with every event, see Reputation
+	reputations map[uuid.UUID]Reputation
+
+	// set by StreamJSONL / OpenJSONL
+	jsonl     *json.Encoder
+	jsonlFile io.Closer
+
+	// agents request information (and are recorded) from their own goroutines
+	mu sync.Mutex
 }
 
 func (sdr *ServerDataRecorder) GetCurrentTurnRecord() *TurnRecord {
@@ -41,6 +59,8 @@ func (sdr *ServerDataRecorder) GetCurrentTurnRecord() *TurnRecord {
 func CreateRecorder() *ServerDataRecorder {
 	return &ServerDataRecorder{
 		TurnRecords:      []TurnRecord{},
+		Events:           []EventRecord{},
+		reputations:      make(map[uuid.UUID]Reputation),
 		currentIteration: -1, // to start from 0
 		currentTurn:      -1,
 	}
@@ -54,13 +74,46 @@ func (sdr *ServerDataRecorder) RecordNewIteration() {
 	sdr.TurnRecords = append(sdr.TurnRecords, NewTurnRecord(sdr.currentTurn, sdr.currentIteration))
 }
 
-func (sdr *ServerDataRecorder) RecordNewTurn(agentRecords []AgentRecord, teamRecords []TeamRecord, commonRecord CommonRecord) {
+// Recording into a nil recorder does nothing, so servers built without Init still run
+func (sdr *ServerDataRecorder) RecordNewTurn(agentRecords []AgentRecord, teamRecords []TeamRecord, commonRecord CommonRecord, rollRecords []RollRecord) {
+	if sdr == nil {
+		return
+	}
+	sdr.mu.Lock()
+	defer sdr.mu.Unlock()
 	sdr.currentTurn += 1
 	sdr.TurnRecords = append(sdr.TurnRecords, NewTurnRecord(sdr.currentTurn, sdr.currentIteration))
 
 	sdr.TurnRecords[len(sdr.TurnRecords)-1].AgentRecords = agentRecords
 	sdr.TurnRecords[len(sdr.TurnRecords)-1].TeamRecords = teamRecords
 	sdr.TurnRecords[len(sdr.TurnRecords)-1].CommonRecord = commonRecord
+	sdr.TurnRecords[len(sdr.TurnRecords)-1].RollRecords = rollRecords
+	sdr.turnEventsStart = len(sdr.Events)
+	sdr.writeJSONL(jsonlLine{Turn: sdr.GetCurrentTurnRecord()})
+}
+
+func (sdr *ServerDataRecorder) RecordEvent(event EventRecord) {
+	if sdr == nil {
+		return
+	}
+	sdr.mu.Lock()
+	defer sdr.mu.Unlock()
+	sdr.Events = append(sdr.Events, event)
+	if sdr.reputations == nil {
+		sdr.reputations = make(map[uuid.UUID]Reputation)
+	}
+	applyReputation(sdr.reputations, event)
+	sdr.writeJSONL(jsonlLine{Event: &event})
+}
+
+// EventsSinceLastTurn returns the events recorded since the last call to RecordNewTurn
+func (sdr *ServerDataRecorder) EventsSinceLastTurn() []EventRecord {
+	if sdr == nil {
+		return nil
+	}
+	sdr.mu.Lock()
+	defer sdr.mu.Unlock()
+	return sdr.Events[sdr.turnEventsStart:]
 }
 
 func (sdr *ServerDataRecorder) GamePlaybackSummary() {
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
@@ -774,6 +775,20 @@ func ExportToCSV(recorder *ServerDataRecorder, outputDir string) error {
 		return fmt.Errorf("failed to export common records: %v", err)
 	}
 
+	// Export dice rolls (flattened from turn records)
+	var allRollRecords []RollRecord
+	for _, turn := range recorder.TurnRecords {
+		allRollRecords = append(allRollRecords, turn.RollRecords...)
+	}
+	if err := exportStructSliceToCSV(allRollRecords, filepath.Join(outputDir, "roll_records.csv")); err != nil {
+		return fmt.Errorf("failed to export roll records: %v", err)
+	}
+
+	// Export events, one column per payload field (empty for the other event types)
+	if err := exportStructSliceToCSV(recorder.Events, filepath.Join(outputDir, "event_records.csv")); err != nil {
+		return fmt.Errorf("failed to export event records: %v", err)
+	}
+
 	return nil
 }
 
@@ -804,6 +819,23 @@ func exportStructSliceToCSV(data interface{}, filepath string) error {
 	structType := v.Index(0).Type()
 
 	// Write header
+	if err := writer.Write(csvHeaders(structType, "")); err != nil {
+		return err
+	}
+
+	// Write data
+	for i := 0; i < v.Len(); i++ {
+		if err := writer.Write(csvValues(v.Index(i))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvHeaders lists the exported fields of the struct. Fields holding a struct (or a pointer
+// to one) are flattened into a column per field, named e.g. "Audit.Cost".
+func csvHeaders(structType reflect.Type, prefix string) []string {
 	var headers []string
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
@@ -811,46 +843,78 @@ func exportStructSliceToCSV(data interface{}, filepath string) error {
 		if field.PkgPath != "" {
 			continue
 		}
-		headers = append(headers, field.Name)
-	}
-	if err := writer.Write(headers); err != nil {
-		return err
+		if nested, ok := nestedStruct(field.Type); ok {
+			headers = append(headers, csvHeaders(nested, prefix+field.Name+".")...)
+			continue
+		}
+		headers = append(headers, prefix+field.Name)
 	}
+	return headers
+}
 
-	// Write data
-	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i)
-		var row []string
-		for j := 0; j < item.Type().NumField(); j++ {
-			field := item.Type().Field(j)
-			// Skip unexported fields
-			if field.PkgPath != "" {
-				continue
-			}
-			fieldValue := item.Field(j)
-			// Convert the field value to string based on its type
-			var strValue string
-			switch fieldValue.Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				strValue = strconv.FormatInt(fieldValue.Int(), 10)
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				strValue = strconv.FormatUint(fieldValue.Uint(), 10)
-			case reflect.Float32, reflect.Float64:
-				strValue = strconv.FormatFloat(fieldValue.Float(), 'f', -1, 64)
-			case reflect.Bool:
-				strValue = strconv.FormatBool(fieldValue.Bool())
-			case reflect.String:
-				strValue = fieldValue.String()
-			default:
-				// For complex types, use fmt.Sprint
-				strValue = fmt.Sprint(fieldValue.Interface())
+// csvValues returns the row for item, in the order of csvHeaders
+func csvValues(item reflect.Value) []string {
+	var row []string
+	for j := 0; j < item.Type().NumField(); j++ {
+		field := item.Type().Field(j)
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldValue := item.Field(j)
+		if nested, ok := nestedStruct(field.Type); ok {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					// leave the columns of a missing struct empty
+					row = append(row, make([]string, len(csvHeaders(nested, "")))...)
+					continue
+				}
+				fieldValue = fieldValue.Elem()
 			}
-			row = append(row, strValue)
+			row = append(row, csvValues(fieldValue)...)
+			continue
 		}
-		if err := writer.Write(row); err != nil {
-			return err
+		// Convert the field value to string based on its type
+		var strValue string
+		switch fieldValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			strValue = strconv.FormatInt(fieldValue.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			strValue = strconv.FormatUint(fieldValue.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			strValue = strconv.FormatFloat(fieldValue.Float(), 'f', -1, 64)
+		case reflect.Bool:
+			strValue = strconv.FormatBool(fieldValue.Bool())
+		case reflect.String:
+			strValue = fieldValue.String()
+		case reflect.Slice:
+			// e.g. the members of a team, separated by semicolons
+			items := make([]string, fieldValue.Len())
+			for k := range items {
+				items[k] = fmt.Sprint(fieldValue.Index(k).Interface())
+			}
+			strValue = strings.Join(items, ";")
+		case reflect.Map:
+			// key=value pairs in key order, e.g. the ranks of a team
+			items := make([]string, 0, fieldValue.Len())
+			for _, key := range fieldValue.MapKeys() {
+				items = append(items, fmt.Sprintf("%v=%v", key.Interface(), fieldValue.MapIndex(key).Interface()))
+			}
+			sort.Strings(items)
+			strValue = strings.Join(items, ";")
+		default:
+			// For complex types, use fmt.Sprint
+			strValue = fmt.Sprint(fieldValue.Interface())
 		}
+		row = append(row, strValue)
 	}
+	return row
+}
 
-	return nil
+// nestedStruct reports whether a field of type t is a struct (or a pointer to one) to flatten
+func nestedStruct(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
 }
@@ -0,0 +1,139 @@
+package gameRecorder
+
+import (
+	"github.com/google/uuid"
+)
+
+// EventType names something that happened during a turn, outside the per-turn state
+type EventType string
+
+const (
+	// an agent voted to audit another agent (AuditVote)
+	AuditVoteCastEvent EventType = "AuditVoteCast"
+	// a team audited an agent (Audit)
+	AuditExecutedEvent EventType = "AuditExecuted"
+	// an agent lost score after an audit (Punishment)
+	PunishmentAppliedEvent EventType = "PunishmentApplied"
+	// an agent was removed from its team
+	AgentKickedEvent EventType = "AgentKicked"
+	// an agent chose to leave its team
+	AgentLeftEvent EventType = "AgentLeft"
+	// an agent fell below the threshold (Killed)
+	AgentKilledEvent EventType = "AgentKilled"
+	// an agent was alive at the end of an iteration
+	IterationSurvivedEvent EventType = "IterationSurvived"
+	// a team elected a leader, AgentID is the new leader (Leader)
+	LeaderElectedEvent EventType = "LeaderElected"
+	// an orphan was accepted by a team (Orphan)
+	OrphanAllocatedEvent EventType = "OrphanAllocated"
+	// a team voted against taking in an orphan, TeamID is that team (Orphan)
+	OrphanRejectedEvent EventType = "OrphanRejected"
+	// orphans formed a new team among themselves, or were put in one by the server
+	// after being rejected too often (Orphan)
+	OrphanTeamFormedEvent EventType = "OrphanTeamFormed"
+	// an agent finished its probation and became a full member of its team
+	ProbationEndedEvent EventType = "ProbationEnded"
+	// an agent ranked the AoAs in its team's vote on one (AoAVote)
+	AoAVoteCastEvent EventType = "AoAVoteCast"
+	// a team adopted an AoA (AoA)
+	AoAAdoptedEvent EventType = "AoAAdopted"
+	// an agent asked the server for information (Request)
+	InformationRequestedEvent EventType = "InformationRequested"
+)
+
+// EventRecord is a record of a single event. AgentID is the agent the event is about
+// (the voter for AuditVoteCast and AoAVoteCast) and TeamID its team, either is Nil if it does not apply.
+// Only the payload of the event's type is set.
+type EventRecord struct {
+	TurnNumber      int
+	IterationNumber int
+	Type            EventType
+
+	AgentID uuid.UUID
+	TeamID  uuid.UUID
+
+	AuditVote  *AuditVoteCast        `json:",omitempty"`
+	Audit      *AuditExecuted        `json:",omitempty"`
+	Punishment *PunishmentApplied    `json:",omitempty"`
+	Killed     *AgentKilled          `json:",omitempty"`
+	Leader     *LeaderElected        `json:",omitempty"`
+	AoAVote    *AoAVoteCast          `json:",omitempty"`
+	AoA        *AoAAdopted           `json:",omitempty"`
+	Request    *InformationRequested `json:",omitempty"`
+	Orphan     *OrphanPlacement      `json:",omitempty"`
+}
+
+type AuditVoteCast struct {
+	// ContributionAudit or WithdrawalAudit
+	Phase      string
+	VotedForID uuid.UUID
+}
+
+type AuditExecuted struct {
+	Phase string
+	// whether the audit found an infraction
+	Caught bool
+	// taken from the common pool to run the audit
+	Cost int
+}
+
+type PunishmentApplied struct {
+	// taken from the agent's score
+	Amount      int
+	ScoreBefore int
+	// change of the team's common pool during the punishment
+	PoolDelta int
+}
+
+type AgentKilled struct {
+	Score     int
+	Threshold int
+}
+
+type LeaderElected struct {
+	// number of votes the leader received
+	Votes int
+}
+
+type AoAVoteCast struct {
+	// the AoA IDs, most wanted first
+	Ranking []int
+}
+
+type AoAAdopted struct {
+	AoAID int
+	// the AoA was set by the experiment instead of voted on
+	Forced bool
+}
+
+type InformationRequested struct {
+	// name of the server function, e.g. GetTeamCommonPool
+	Query string
+	// the agent or team asked about, Nil if none
+	SubjectID uuid.UUID
+	// false if the rules do not let the agent know the answer
+	Allowed bool
+}
+
+type OrphanPlacement struct {
+	// turns the orphan had spent in the orphan pool
+	Waited int
+	// teams that had rejected the orphan, including this one for OrphanRejected
+	Rejections int
+	// OrphanAllocated: paid by the orphan into the team's common pool (see common.IAdmissionAoA)
+	EntryFee int
+	// OrphanAllocated: turns the orphan is on probation for
+	Probation int
+	// OrphanTeamFormed: the orphans formed the team themselves, rather than the server
+	Negotiated bool
+}
+
+func NewEventRecord(turnNumber int, iterationNumber int, eventType EventType, agentID uuid.UUID, teamID uuid.UUID) EventRecord {
+	return EventRecord{
+		TurnNumber:      turnNumber,
+		IterationNumber: iterationNumber,
+		Type:            eventType,
+		AgentID:         agentID,
+		TeamID:          teamID,
+	}
+}
@@ -0,0 +1,113 @@
+package gameRecorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// jsonlLine is one line of a JSON Lines game record: either a complete turn (agent,
+// team and common records) or a single event, in the order they were recorded
+type jsonlLine struct {
+	Turn  *TurnRecord  `json:"turn,omitempty"`
+	Event *EventRecord `json:"event,omitempty"`
+}
+
+// StreamJSONL writes every turn and event recorded from now on to w, one JSON object
+// per line, as soon as it is recorded
+func (sdr *ServerDataRecorder) StreamJSONL(w io.Writer) {
+	sdr.jsonl = json.NewEncoder(w)
+}
+
+// OpenJSONL creates (or truncates) the file at path and streams the record to it.
+// Each line is written with a single write, so a crash loses at most the line being
+// written. Call Close at the end of the run.
+func (sdr *ServerDataRecorder) OpenJSONL(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	sdr.StreamJSONL(f)
+	sdr.jsonlFile = f
+	return nil
+}
+
+// Close stops streaming and closes the file opened by OpenJSONL
+func (sdr *ServerDataRecorder) Close() error {
+	if sdr == nil {
+		return nil
+	}
+	sdr.mu.Lock()
+	defer sdr.mu.Unlock()
+	sdr.jsonl = nil
+	if sdr.jsonlFile == nil {
+		return nil
+	}
+	err := sdr.jsonlFile.Close()
+	sdr.jsonlFile = nil
+	return err
+}
+
+func (sdr *ServerDataRecorder) writeJSONL(line jsonlLine) {
+	if sdr.jsonl == nil {
+		return
+	}
+	if err := sdr.jsonl.Encode(line); err != nil {
+		// keep the simulation running, the in-memory record is still complete
+		log.Printf("[gameRecorder] Failed to write JSONL record, streaming stopped: %v\n", err)
+		sdr.jsonl = nil
+	}
+}
+
+// LoadJSONL rebuilds a recorder from a file written by OpenJSONL, e.g. to regenerate
+// the visualisation with CreatePlaybackHTML without rerunning the simulation
+func LoadJSONL(path string) (*ServerDataRecorder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadJSONL(f)
+}
+
+// ReadJSONL rebuilds a recorder, with the reputation of every agent, from a JSON Lines record
+func ReadJSONL(r io.Reader) (*ServerDataRecorder, error) {
+	sdr := CreateRecorder()
+	scanner := bufio.NewScanner(r)
+	// turn lines hold every agent record and can be long
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var line jsonlLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		switch {
+		case line.Turn != nil:
+			sdr.TurnRecords = append(sdr.TurnRecords, *line.Turn)
+			sdr.currentTurn = line.Turn.TurnNumber
+			sdr.currentIteration = line.Turn.IterationNumber
+		case line.Event != nil:
+			sdr.Events = append(sdr.Events, *line.Event)
+			applyReputation(sdr.reputations, *line.Event)
+		default:
+			return nil, fmt.Errorf("line %d: neither a turn nor an event", lineNumber)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sdr, nil
+}
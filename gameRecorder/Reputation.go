@@ -0,0 +1,60 @@
+package gameRecorder
+
+import (
+	"github.com/google/uuid"
+)
+
+// Reputation is the public record of an agent, derived from the events recorded about it
+type Reputation struct {
+	// times the agent chose to leave its team
+	TeamsLeft int
+	// times the agent was removed from its team
+	TimesKicked int
+	// audits that caught the agent
+	FailedAudits int
+	// iterations the agent was alive at the end of
+	IterationsSurvived int
+}
+
+// Count the event towards the reputation of the agent it is about
+func applyReputation(reputations map[uuid.UUID]Reputation, event EventRecord) {
+	if event.AgentID == uuid.Nil {
+		return
+	}
+	reputation := reputations[event.AgentID]
+	switch event.Type {
+	case AgentLeftEvent:
+		reputation.TeamsLeft++
+	case AgentKickedEvent:
+		reputation.TimesKicked++
+	case AuditExecutedEvent:
+		if event.Audit == nil || !event.Audit.Caught {
+			return
+		}
+		reputation.FailedAudits++
+	case IterationSurvivedEvent:
+		reputation.IterationsSurvived++
+	default:
+		return
+	}
+	reputations[event.AgentID] = reputation
+}
+
+// Reputations rebuilds the reputation of every agent from a list of events, e.g. those of a replayed record
+func Reputations(events []EventRecord) map[uuid.UUID]Reputation {
+	reputations := make(map[uuid.UUID]Reputation)
+	for _, event := range events {
+		applyReputation(reputations, event)
+	}
+	return reputations
+}
+
+// GetReputation returns the reputation of the agent from the events recorded so far
+func (sdr *ServerDataRecorder) GetReputation(agentID uuid.UUID) Reputation {
+	if sdr == nil {
+		return Reputation{}
+	}
+	sdr.mu.Lock()
+	defer sdr.mu.Unlock()
+	return sdr.reputations[agentID]
+}
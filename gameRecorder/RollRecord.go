@@ -0,0 +1,25 @@
+package gameRecorder
+
+import (
+	"github.com/google/uuid"
+)
+
+// RollRecord is a record of the dice an agent rolled in a turn
+type RollRecord struct {
+	// basic info fields
+	TurnNumber      int
+	IterationNumber int
+	AgentID         uuid.UUID
+	TeamID          uuid.UUID
+
+	// Team2 leader that made the stick decisions, Nil if the agent decided itself
+	ControlledBy uuid.UUID
+
+	Rolls []int
+	// stick (true) or roll again (false) decisions in the order they were taken. The agent
+	// decides after each roll that did not bust, a Team2 leader also decides before the first roll.
+	Decisions []bool
+	// the last roll was not higher than the one before, so the turn score was lost
+	Bust      bool
+	TurnScore int
+}
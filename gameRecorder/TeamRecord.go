@@ -5,15 +5,29 @@ import (
 	"github.com/google/uuid"
 )
 
-// AgentRecord is a record of an agent's state at a given turn
+// TeamRecord is a record of a team's state at a given turn
 type TeamRecord struct {
 	// basic info fields
 	TurnNumber      int
 	IterationNumber int
 	TeamID          uuid.UUID
+	TeamAoAID       int
 
 	// turn-specific fields
 	TeamCommonPool int
-	AgentsAlive    []uuid.UUID
-	AgentsDead     []uuid.UUID
+	Agents         []uuid.UUID // members at the end of the turn, all alive (the dead leave their team)
+	AgentsDead     []uuid.UUID // members that died this iteration
+
+	// flows through the common pool during the turn
+	TotalContributed     int
+	TotalWithdrawn       int
+	AuditCostPaid        int
+	PunishmentsCollected int
+
+	// AoA-specific fields, empty if the AoA has no leader / ranks
+	Leader uuid.UUID
+	Ranks  map[uuid.UUID]string
+
+	// members on probation and the turns they have left, empty if there are none
+	Probation map[uuid.UUID]int
 }
@@ -4,19 +4,16 @@ go 1.23.2
 
 require (
 	github.com/MattSScott/basePlatformSOMAS/v2 v2.1.0
+	github.com/go-echarts/go-echarts/v2 v2.4.5
 	github.com/google/uuid v1.3.0
+	golang.org/x/exp v0.0.0-20241204233417-43b7b7cde48d
 )
 
 require (
-	github.com/go-echarts/go-echarts/v2 v2.4.5 // indirect
-	golang.org/x/exp v0.0.0-20241204233417-43b7b7cde48d // indirect
-)
-
-require (
-	bou.ke/monkey v1.0.2 // indirect
+	bou.ke/monkey v1.0.2
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
+	github.com/stretchr/testify v1.10.0
 	gonum.org/v1/gonum v0.15.1
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
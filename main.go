@@ -1,22 +1,21 @@
 package main
 
 import (
+	"flag"
 	"io"
 	"log"
 	"os"
 	"time"
 
-	"github.com/google/uuid"
-
-	baseServer "github.com/MattSScott/basePlatformSOMAS/v2/pkg/server"
-
-	agents "github.com/ADimoska/SOMASExtended/agents"
-	common "github.com/ADimoska/SOMASExtended/common"
+	config "github.com/ADimoska/SOMASExtended/config"
 	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
-	envServer "github.com/ADimoska/SOMASExtended/server"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a YAML or JSON scenario file (defaults to the built-in scenario)")
+	recordPath := flag.String("record", "visualization_output/game_record.jsonl", "JSON Lines file the game record is streamed to (empty to disable)")
+	flag.Parse()
+
 	// Create logs directory if it doesn't exist
 	if err := os.MkdirAll("logs", 0755); err != nil {
 		log.Fatalf("Failed to create logs directory: %v", err)
@@ -41,53 +40,26 @@ func main() {
 
 	log.Println("main function started.")
 
-	// agent configuration
-	agentConfig := agents.AgentConfig{
-		InitScore:    0,
-		VerboseLevel: 10,
+	// load the scenario, falling back to the default population
+	simConfig := config.DefaultConfig()
+	if *configPath != "" {
+		simConfig, err = config.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
 	}
 
-	serv := &envServer.EnvironmentServer{
-		// note: the zero turn is used for team forming
-		BaseServer: baseServer.CreateBaseServer[common.IExtendedAgent](
-			2,                   //  iterations
-			100,                 //  turns per iteration
-			50*time.Millisecond, //  max duration
-			10),                 //  message bandwidth
-		Teams: make(map[uuid.UUID]*common.Team),
-	}
-	serv.Init(
-		3, // turns to apply threshold once
-	)
-	serv.SetGameRunner(serv)
-
-	const numAgents int = 10
-
-	agentPopulation := []common.IExtendedAgent{}
-	for i := 0; i < numAgents; i++ {
-		agentPopulation = append(agentPopulation, agents.Team4_CreateAgent(serv, agentConfig))
-		agentPopulation = append(agentPopulation, agents.Team2_CreateAgent(serv, agentConfig))
-		// agentPopulation = append(agentPopulation, agents.GetBaseAgents(serv, agentConfig))
-		// Add other teams' agents here
-	}
-
-	for i := 0; i < numAgents-2; i++ {
-		// Add mostly honest agents
-		agentPopulation = append(agentPopulation, agents.Create_Team1Agent(serv, agentConfig, agents.Honest))
+	serv, err := config.BuildSimulation(simConfig)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
 	}
 
-	// Add a short term and long term cheater agent from team 1
-	team1ShortTermCheater := agents.Create_Team1Agent(serv, agentConfig, agents.CheatShortTerm)
-	log.Printf("Team1 %v is of type CheatShortTerm", team1ShortTermCheater.GetID())
-	agentPopulation = append(agentPopulation, team1ShortTermCheater)
-
-	team1LongTermCheater := agents.Create_Team1Agent(serv, agentConfig, agents.CheatLongTerm)
-	log.Printf("Team1 %v is of type CheatLongTerm", team1LongTermCheater.GetID())
-	agentPopulation = append(agentPopulation, team1LongTermCheater)
-
-	for i, agent := range agentPopulation {
-		agent.SetName(i)
-		serv.AddAgent(agent)
+	// stream the record as the game runs, so it survives a crash
+	if *recordPath != "" {
+		if err := serv.DataRecorder.OpenJSONL(*recordPath); err != nil {
+			log.Fatalf("Failed to open game record: %v", err)
+		}
+		defer serv.DataRecorder.Close()
 	}
 
 	//serv.ReportMessagingDiagnostics()
@@ -0,0 +1,167 @@
+package environmentServer
+
+import (
+	"log"
+	"slices"
+
+	"github.com/MattSScott/basePlatformSOMAS/v2/pkg/agent"
+	"github.com/google/uuid"
+
+	common "github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+)
+
+// agentView is the server as seen by one agent (common.IAgentServer). Messaging is
+// passed through to the server, everything else is checked against the owner.
+type agentView struct {
+	agent.IExposedServerFunctions[common.IExtendedAgent]
+	server  *EnvironmentServer
+	agentID uuid.UUID
+}
+
+// Create a view for an agent that has not been created yet, the agent's constructor
+// passes it to agent.CreateBaseAgent and then calls SetOwner with the new ID
+func (cs *EnvironmentServer) NewAgentView() common.IAgentServer {
+	return &agentView{IExposedServerFunctions: cs, server: cs}
+}
+
+func (v *agentView) SetOwner(agentID uuid.UUID) {
+	if v.agentID == uuid.Nil {
+		v.agentID = agentID
+	}
+}
+
+// The owner's team, as the server knows it
+func (v *agentView) ownTeam() *common.Team {
+	owner, ok := v.server.GetAgentMap()[v.agentID]
+	if !ok {
+		return nil
+	}
+	team := v.server.GetTeamFromTeamID(owner.GetTeamID())
+	if team == nil || !slices.Contains(team.Agents, v.agentID) {
+		return nil
+	}
+	return team
+}
+
+func (v *agentView) ownTeamID() uuid.UUID {
+	if team := v.ownTeam(); team != nil {
+		return team.TeamID
+	}
+	return uuid.Nil
+}
+
+func (v *agentView) record(query string, subjectID uuid.UUID, allowed bool) {
+	event := v.server.newEvent(gameRecorder.InformationRequestedEvent, v.agentID, v.ownTeamID())
+	event.Request = &gameRecorder.InformationRequested{Query: query, SubjectID: subjectID, Allowed: allowed}
+	v.server.DataRecorder.RecordEvent(event)
+}
+
+// Agents can not inspect each other, they have to ask through messages
+func (v *agentView) AccessAgentByID(agentID uuid.UUID) common.IExtendedAgent {
+	v.record("AccessAgentByID", agentID, false)
+	log.Printf("[server] Agent %v was refused access to agent %v\n", v.agentID, agentID)
+	return nil
+}
+
+func (v *agentView) CreateAndInitTeamWithAgents(agentIDs []uuid.UUID) uuid.UUID {
+	return v.server.CreateAndInitTeamWithAgents(agentIDs)
+}
+
+func (v *agentView) AddAgentToTeam(agentID uuid.UUID, teamID uuid.UUID) {
+	if agentID != v.agentID {
+		log.Printf("[server] Agent %v can not add agent %v to a team\n", v.agentID, agentID)
+		return
+	}
+	v.server.AddAgentToTeam(agentID, teamID)
+}
+
+func (v *agentView) GetTeamIDs() []uuid.UUID {
+	v.record("GetTeamIDs", uuid.Nil, true)
+	return v.server.GetTeamIDs()
+}
+
+func (v *agentView) GetAgentsInTeam(teamID uuid.UUID) []uuid.UUID {
+	v.record("GetAgentsInTeam", teamID, true)
+	team := v.server.GetTeamFromTeamID(teamID)
+	if team == nil {
+		return nil
+	}
+	// a copy, so the agent can not change the team
+	return slices.Clone(team.Agents)
+}
+
+func (v *agentView) CheckAgentAlreadyInTeam(agentID uuid.UUID) bool {
+	v.record("CheckAgentAlreadyInTeam", agentID, true)
+	return v.server.CheckAgentAlreadyInTeam(agentID)
+}
+
+func (v *agentView) GetAgentTeamID(agentID uuid.UUID) uuid.UUID {
+	v.record("GetAgentTeamID", agentID, true)
+	if agent, ok := v.server.GetAgentMap()[agentID]; ok {
+		return agent.GetTeamID()
+	}
+	return uuid.Nil
+}
+
+func (v *agentView) IsAgentDead(agentID uuid.UUID) bool {
+	v.record("IsAgentDead", agentID, true)
+	return v.server.IsAgentDead(agentID)
+}
+
+func (v *agentView) GetReputation(agentID uuid.UUID) gameRecorder.Reputation {
+	v.record("GetReputation", agentID, true)
+	return v.server.GetReputation(agentID)
+}
+
+func (v *agentView) GetResourceGame() common.IResourceGame {
+	v.record("GetResourceGame", uuid.Nil, true)
+	return v.server.GetResourceGame()
+}
+
+func (v *agentView) GetTeamAoA() common.TeamAoAView {
+	team := v.ownTeam()
+	v.record("GetTeamAoA", v.ownTeamID(), team != nil && team.TeamAoA != nil)
+	if team == nil || team.TeamAoA == nil {
+		return nil
+	}
+	return common.NewTeamAoAView(team.TeamAoAID, team.TeamAoA)
+}
+
+func (v *agentView) GetTeamAoAID() int {
+	team := v.ownTeam()
+	v.record("GetTeamAoAID", v.ownTeamID(), team != nil)
+	if team == nil {
+		return 0
+	}
+	return team.TeamAoAID
+}
+
+func (v *agentView) GetTeamCommonPool() int {
+	team := v.ownTeam()
+	v.record("GetTeamCommonPool", v.ownTeamID(), team != nil)
+	if team == nil {
+		return 0
+	}
+	return team.GetCommonPool()
+}
+
+func (v *agentView) GetTeamProbation() map[uuid.UUID]int {
+	team := v.ownTeam()
+	v.record("GetTeamProbation", v.ownTeamID(), team != nil)
+	if team == nil {
+		return map[uuid.UUID]int{}
+	}
+	return team.GetProbationers()
+}
+
+func (v *agentView) GetAgentKilledScore(agentID uuid.UUID) int {
+	// the dead have already left the team, so look for them among its dead members
+	team := v.ownTeam()
+	allowed := team != nil && slices.Contains(v.server.deadTeamMembers[team.TeamID], agentID)
+	v.record("GetAgentKilledScore", agentID, allowed)
+	if !allowed {
+		return 0
+	}
+	return v.server.GetAgentKilledScore(agentID)
+}
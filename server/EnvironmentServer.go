@@ -1,10 +1,9 @@
 package environmentServer
 
 import (
-	"fmt"
 	"log"
 	"math/rand"
-	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -27,6 +26,17 @@ type EnvironmentServer struct {
 	deadAgents          []common.IExtendedAgent
 	orphanPool          OrphanPoolType
 
+	// members of each team that died this iteration
+	deadTeamMembers map[uuid.UUID][]uuid.UUID
+	// state of each team's last turn, for recording
+	teamTurnStates map[uuid.UUID]*common.TurnState
+	// positive lies told by each agent since the start of the game
+	dishonesty map[uuid.UUID]int
+	// dice rolled this turn
+	turnRolls []gameRecorder.RollRecord
+	// agents that died this turn, in order
+	turnDeaths []uuid.UUID
+
 	// data recorder
 	DataRecorder *gameRecorder.ServerDataRecorder
 
@@ -36,313 +46,28 @@ type EnvironmentServer struct {
 	thresholdTurns         int
 	thresholdAppliedInTurn bool
 	allAgentsDead          bool
-}
-
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-func (cs *EnvironmentServer) RunTurnDefault(team *common.Team) {
-	log.Println("\nRunning turn for team ", team.TeamID)
-	// Sum of contributions from all agents in the team for this turn
-	agentContributionsTotal := 0
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		if agent == nil || agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentID) {
-			continue
-		}
-
-		if team.TeamAoAID == 2 && team.TeamAoA.(*common.Team2AoA).GetRollsLeft(agentID) > 0 {
-			team.TeamAoA.(*common.Team2AoA).RollOnce(agentID)
-			cs.OverrideAgentRolls(agentID, team.TeamAoA.(*common.Team2AoA).GetLeader())
-		} else {
-			agent.StartRollingDice(agent)
-		}
-
-		agentActualContribution := agent.GetActualContribution(agent)
-		agentContributionsTotal += agentActualContribution
-		agentStatedContribution := agent.GetStatedContribution(agent)
-
-		agent.StateContributionToTeam(agent)
-		agentScore := agent.GetTrueScore()
-		// Update audit result for this agent
-		team.TeamAoA.SetContributionAuditResult(agentID, agentScore, agentActualContribution, agentStatedContribution)
-		agent.SetTrueScore(agentScore - agentActualContribution)
-	}
-
-	// Update common pool with total contribution from this team
-	// 	Agents do not get to see the common pool before deciding their contribution
-	//  Different to the withdrawal phase!
-	team.SetCommonPool(team.GetCommonPool() + agentContributionsTotal)
-
-	// Initiate Contribution Audit vote
-	contributionAuditVotes := []common.Vote{}
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		vote := agent.GetContributionAuditVote()
-		contributionAuditVotes = append(contributionAuditVotes, vote)
-	}
-
-	// Execute Contribution Audit if necessary
-	if agentToAudit := team.TeamAoA.GetVoteResult(contributionAuditVotes); agentToAudit != uuid.Nil {
-		auditResult := team.TeamAoA.GetContributionAuditResult(agentToAudit)
-
-		if auditResult {
-			cs.ApplyPunishment(team, agentToAudit)
-			if team.TeamAoAID == 2 {
-				if agentToAudit == team.TeamAoA.(*common.Team2AoA).GetLeader() {
-					cs.ElectNewLeader(team.TeamID)
-				}
-				if team.TeamAoA.(*common.Team2AoA).GetOffences(agentToAudit) == 3 {
-					cs.RemoveAgentFromTeam(agentToAudit)
-				}
-			}
-		}
-
-		for _, agentID := range team.Agents {
-			agent := cs.GetAgentMap()[agentID]
-			agent.SetAgentContributionAuditResult(agentToAudit, auditResult)
-		}
-	}
-
-	orderedAgents := team.TeamAoA.GetWithdrawalOrder(team.Agents)
-	for _, agentID := range orderedAgents {
-		agent := cs.GetAgentMap()[agentID]
-		if agent == nil || agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentID) {
-			continue
-		}
-
-		// Pass the current pool value to agent's methods
-		currentPool := team.GetCommonPool()
-		agentActualWithdrawal := agent.GetActualWithdrawal(agent)
-		if agentActualWithdrawal > currentPool {
-			agentActualWithdrawal = currentPool // Ensure withdrawal does not exceed available pool
-		}
-		agentStatedWithdrawal := agent.GetStatedWithdrawal(agent)
-
-		agentScore := agent.GetTrueScore()
-		// Update audit result for this agent
-		team.TeamAoA.SetWithdrawalAuditResult(agentID, agentScore, agentActualWithdrawal, agentStatedWithdrawal, team.GetCommonPool())
-		agent.SetTrueScore(agentScore + agentActualWithdrawal)
-
-		// Update the common pool after each withdrawal so agents can see the updated pool before deciding their withdrawal.
-		//  Different to the contribution phase!
-		team.SetCommonPool(currentPool - agentActualWithdrawal)
-		log.Printf("[server] Agent %v withdrew %v. Remaining pool: %v\n", agentID, agentActualWithdrawal, team.GetCommonPool())
-	}
-
-	stateWithdrawOrder := make([]uuid.UUID, len(team.Agents))
-	copy(stateWithdrawOrder, team.Agents)
-	// Shuffle the order of agents to broadcast withdrawal amounts
-	rand.Shuffle(len(stateWithdrawOrder), func(i, j int) {
-		stateWithdrawOrder[i], stateWithdrawOrder[j] = stateWithdrawOrder[j], stateWithdrawOrder[i]
-	})
-
-	for _, agentId := range stateWithdrawOrder {
-		agent := cs.GetAgentMap()[agentId]
-		if agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentId) {
-			continue
-		}
-		agent.StateWithdrawalToTeam(agent)
-	}
-
-	// Initiate Withdrawal Audit vote
-	withdrawalAuditVotes := []common.Vote{}
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		vote := agent.GetWithdrawalAuditVote()
-		withdrawalAuditVotes = append(withdrawalAuditVotes, vote)
-	}
-
-	// Execute Withdrawal Audit if necessary
-	if agentToAudit := team.TeamAoA.GetVoteResult(withdrawalAuditVotes); agentToAudit != uuid.Nil {
-		auditResult := team.TeamAoA.GetWithdrawalAuditResult(agentToAudit)
-
-		if auditResult {
-			cs.ApplyPunishment(team, agentToAudit)
-
-			if team.TeamAoAID == 2 {
-				if agentToAudit == team.TeamAoA.(*common.Team2AoA).GetLeader() {
-					cs.ElectNewLeader(team.TeamID)
-				}
-				if team.TeamAoA.(*common.Team2AoA).GetOffences(agentToAudit) == 3 {
-					cs.RemoveAgentFromTeam(agentToAudit)
-				}
-			}
-		}
-
-		for _, agentID := range team.Agents {
-			agent := cs.GetAgentMap()[agentID]
-			agent.SetAgentWithdrawalAuditResult(agentToAudit, auditResult)
-		}
-	}
-}
-
-func (cs *EnvironmentServer) RunTurnTeam4(team *common.Team) {
-	log.Println("\nRunning AoA 4 Variant turn for team ", team.TeamID)
-	// Sum of contributions from all agents in the team for this turn
-	agentContributionsTotal := 0
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		if agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentID) {
-			continue
-		}
-		// Override agent rolls for testing purposes
-		// agentList := []uuid.UUID{agentID}
-		// cs.OverrideAgentRolls(agentID, agentList, 1)
-		agent.StartRollingDice(agent)
-		agentActualContribution := agent.GetActualContribution(agent)
-		agentContributionsTotal += agentActualContribution
-		agentStatedContribution := agent.GetStatedContribution(agent)
-
-		agent.StateContributionToTeam(agent)
-		agentScore := agent.GetTrueScore()
-		// Update audit result for this agent
-		team.TeamAoA.SetContributionAuditResult(agentID, agentScore, agentActualContribution, agentStatedContribution)
-		agent.SetTrueScore(agentScore - agentActualContribution)
-	}
-
-	// ***************
-	rankUpVoteMap := make(map[uuid.UUID]map[uuid.UUID]int)
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		agentRankMap := agent.Team4_GetRankUpVote()
-		rankUpVoteMap[agentID] = agentRankMap
-	}
-	team.TeamAoA.Team4_SetRankUp(rankUpVoteMap)
-
-	// ***************
-
-	// Update common pool with total contribution from this team
-	// 	Agents do not get to see the common pool before deciding their contribution
-	//  Different to the withdrawal phase!
-	team.SetCommonPool(team.GetCommonPool() + agentContributionsTotal)
-
-	// Initiate Contribution Audit vote
-	contributionAuditVotes := []common.Vote{}
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		vote := agent.GetContributionAuditVote()
-		contributionAuditVotes = append(contributionAuditVotes, vote)
-	}
-
-	// Execute Contribution Audit if necessary
-	if agentToAudit := team.TeamAoA.GetVoteResult(contributionAuditVotes); agentToAudit != uuid.Nil {
-		auditResult := team.TeamAoA.GetContributionAuditResult(agentToAudit)
-		for _, agentID := range team.Agents {
-			agent := cs.GetAgentMap()[agentID]
-			agent.SetAgentContributionAuditResult(agentToAudit, auditResult)
-		}
-	}
-
-	// ***************
-	proposedWithdrawalMap := make(map[uuid.UUID]int)
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		agentStatedWithdrawal := agent.Team4_GetProposedWithdrawal(agent)
-		proposedWithdrawalMap[agentID] = agentStatedWithdrawal
-		agent.Team4_StateProposalToTeam()
-
-	}
-	withdrawalVoteMap := make(map[uuid.UUID]map[uuid.UUID]int)
-
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		// Get Map of AgentId and 1 or 0 to proposed withdrawal (for each agent)
-		agentVote := agent.Team4_GetProposedWithdrawalVote()
-		withdrawalVoteMap[agentID] = agentVote
-	}
-	team.TeamAoA.Team4_RunProposedWithdrawalVote(proposedWithdrawalMap, withdrawalVoteMap)
-	// ***************
-
-	orderedAgents := team.TeamAoA.GetWithdrawalOrder(team.Agents)
-	for _, agentID := range orderedAgents {
-		agent := cs.GetAgentMap()[agentID]
-		if agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentID) {
-			continue
-		}
-
-		// Pass the current pool value to agent's methods
-		currentPool := team.GetCommonPool()
-		agentActualWithdrawal := agent.GetActualWithdrawal(agent)
-		if agentActualWithdrawal > currentPool {
-			agentActualWithdrawal = currentPool // Ensure withdrawal does not exceed available pool
-		}
-		agentStatedWithdrawal := agent.GetStatedWithdrawal(agent)
-
-		agentScore := agent.GetTrueScore()
-		// Update audit result for this agent
-		team.TeamAoA.SetWithdrawalAuditResult(agentID, agentScore, agentActualWithdrawal, agentStatedWithdrawal, team.GetCommonPool())
-		agent.SetTrueScore(agentScore + agentActualWithdrawal)
-
-		// Update the common pool after each withdrawal so agents can see the updated pool before deciding their withdrawal.
-		//  Different to the contribution phase!
-		team.SetCommonPool(currentPool - agentActualWithdrawal)
-		log.Printf("[server] Agent %v withdrew %v. Remaining pool: %v\n", agentID, agentActualWithdrawal, team.GetCommonPool())
-	}
-
-	stateWithdrawOrder := make([]uuid.UUID, len(team.Agents))
-	copy(stateWithdrawOrder, team.Agents)
-	// Shuffle the order of agents to broadcast withdrawal amounts
-	rand.Shuffle(len(stateWithdrawOrder), func(i, j int) {
-		stateWithdrawOrder[i], stateWithdrawOrder[j] = stateWithdrawOrder[j], stateWithdrawOrder[i]
-	})
-
-	for _, agentId := range stateWithdrawOrder {
-		agent := cs.GetAgentMap()[agentId]
-		if agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentId) {
-			continue
-		}
-		agent.StateWithdrawalToTeam(agent)
-	}
-
-	// Initiate Withdrawal Audit vote
-	withdrawalAuditVotes := []common.Vote{}
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		vote := agent.GetWithdrawalAuditVote()
-		withdrawalAuditVotes = append(withdrawalAuditVotes, vote)
-	}
-
-	// ***************
-	if agentToAudit := team.TeamAoA.GetVoteResult(withdrawalAuditVotes); agentToAudit != uuid.Nil {
-		agent := cs.GetAgentMap()[agentToAudit]
-		// agentConfession := agent.GetConfession()
-		agent.Team4_StateConfessionToTeam()
-		agentScore := agent.GetTrueScore()
-		punishmentVoteMap := make(map[uuid.UUID]map[int]int)
-		for _, agentID := range team.Agents {
-			agent := cs.GetAgentMap()[agentID]
-			punishmentVote := agent.Team4_GetPunishmentVoteMap()
-			punishmentVoteMap[agentID] = punishmentVote
-		}
-
-		punishmentResult := team.TeamAoA.Team4_HandlePunishmentVote(punishmentVoteMap) * agentScore / 100
-
-		log.Printf("Punishment Result for Agent %v: %d (Agent Score: %d)\n", agent.GetID(), punishmentResult, agentScore)
-
-		newScore := agentScore - punishmentResult
-		agent.SetTrueScore(newScore)
-
-		log.Printf("Updated Score for Agent %v: %d\n", agent.GetID(), agent.GetTrueScore())
-
-		currentPool := team.GetCommonPool()
-		log.Printf("Current Common Pool: %d\n", currentPool)
-
-		team.SetCommonPool(currentPool + punishmentResult)
-		updatedPool := team.GetCommonPool()
-		log.Printf("Updated Common Pool: %d\n", updatedPool)
-
-	}
-	// ***************
-	// Execute Withdrawal Audit if necessary
-	if agentToAudit := team.TeamAoA.GetVoteResult(withdrawalAuditVotes); agentToAudit != uuid.Nil {
-		auditResult := team.TeamAoA.GetWithdrawalAuditResult(agentToAudit)
-		for _, agentID := range team.Agents {
-			agent := cs.GetAgentMap()[agentID]
-			agent.SetAgentWithdrawalAuditResult(agentToAudit, auditResult)
-		}
-	}
+	// whether roundScoreThreshold has been set yet
+	thresholdSet bool
+
+	// every random decision of a run is derived from this generator
+	seed int64
+	rng  *rand.Rand
+
+	// settings, see the setters below
+	majorityVoteThreshold float32
+	forcedAoAID           int
+	teamFormingDelay      time.Duration
+	resourceGame          common.IResourceGame
+	thresholdPolicy       common.IThresholdPolicy
+	thresholdHidden       bool
+	thresholdInfo         common.ThresholdInfoMode
+	thresholdInfoRange    int
+	memoryPolicy          common.MemoryPolicy
+	maxOrphanRejections   int
+	// turns admitted orphans spend on probation, and the share of an equal split of the
+	// pool they may withdraw meanwhile
+	probationTurns           int
+	probationWithdrawalShare float64
 }
 
 func (cs *EnvironmentServer) RunTurn(i, j int) {
@@ -355,28 +80,30 @@ func (cs *EnvironmentServer) RunTurn(i, j int) {
 	// Attempt to allocate the orphans to their preferred teams
 	cs.AllocateOrphans()
 
+	// Orphans that no team will take can form teams of their own
+	cs.FormOrphanTeams()
+
 	cs.turn = j
+	cs.turnDeaths = nil
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		cs.GetAgentMap()[agentID].OnTurnStart(cs.iteration, cs.turn)
+	}
 
 	cs.teamsMutex.Lock()
 	// defer cs.teamsMutex.Unlock()
 
-	for _, team := range cs.Teams {
+	cs.teamTurnStates = make(map[uuid.UUID]*common.TurnState)
+	cs.turnRolls = nil
+	for _, teamID := range common.SortedKeys(cs.Teams) {
+		team := cs.Teams[teamID]
 		if len(team.Agents) == 0 {
 			log.Printf("No agents in team: %s\n", team.TeamID)
 			continue
 		}
-		teamAoA := reflect.TypeOf(team.TeamAoA)
-		switch teamAoA {
-		case reflect.TypeOf(&common.Team4AoA{}):
-			cs.RunTurnTeam4(team)
-		case reflect.TypeOf(&common.Team5AOA{}):
-			cs.RunTurnTeam5(team)
-		default:
-			cs.RunTurnDefault(team)
-
-		}
-
+		cs.RunTeamTurn(team)
 	}
+	cs.updateDishonesty()
+	cs.serveProbation()
 
 	// TODO: Reallocate agents who left their teams during the turn
 
@@ -393,6 +120,20 @@ func (cs *EnvironmentServer) RunTurn(i, j int) {
 	// Only living agents can leave their team
 	cs.ProcessAgentsLeaving()
 
+	results := common.TurnResults{
+		Iteration:        cs.iteration,
+		Turn:             cs.turn,
+		ThresholdApplied: cs.thresholdAppliedInTurn,
+		Died:             cs.turnDeaths,
+		LivingAgents:     len(cs.GetAgentMap()),
+	}
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		// every agent gets its own copy of the deaths
+		agentResults := results
+		agentResults.Died = append([]uuid.UUID{}, results.Died...)
+		cs.GetAgentMap()[agentID].OnTurnEnd(agentResults)
+	}
+
 	// do not record if the turn number is 0
 	if cs.turn > 0 && !cs.allAgentsDead {
 		cs.RecordTurnInfo()
@@ -410,12 +151,16 @@ func (cs *EnvironmentServer) RunStartOfIteration(iteration int) {
 	cs.allAgentsDead = false
 
 	cs.turn = 0
+	// teams are formed again, so orphans start over
+	cs.orphanPool = nil
 
 	// record data
 	// cs.DataRecorder.RecordNewIteration()
 
-	// Initialise random threshold
-	cs.createNewRoundScoreThreshold()
+	// Initialise the threshold (a hidden threshold is only set when it is applied)
+	if !cs.thresholdHidden {
+		cs.createNewRoundScoreThreshold()
+	}
 
 	// Revive all dead agents
 	cs.reviveDeadAgents()
@@ -423,23 +168,31 @@ func (cs *EnvironmentServer) RunStartOfIteration(iteration int) {
 	// reset all agents (make sure their score starts at 0)
 	cs.ResetAgents()
 
+	if !cs.thresholdHidden {
+		cs.notifyThreshold(false)
+	}
+
 	// start team forming
 	cs.StartAgentTeamForming()
 
-	time.Sleep(2 * time.Second)
+	time.Sleep(cs.teamFormingDelay)
 	// take votes at team level and allocate Strategy.
 	cs.allocateAoAs()
 
 	// Perform any functionality needed by AoA at start of iteration.
-	for _, team := range cs.Teams {
-		team.TeamAoA.RunPreIterationAoaLogic(team, cs.GetAgentMap())
+	for _, teamID := range common.SortedKeys(cs.Teams) {
+		team := cs.Teams[teamID]
+		if aoa, ok := team.TeamAoA.(common.IPreIterationAoA); ok {
+			aoa.RunPreIterationAoaLogic(team, cs.GetAgentMap())
+		}
 	}
 }
 
 func runCopelandVote(team *common.Team, cs *EnvironmentServer) []int {
 
-	pairwiseWins := make(map[string]int)
-	copelandScores := make(map[byte]float64)
+	// keyed by the (lower, higher) candidate ids of each pair
+	pairwiseWins := make(map[[2]int]int)
+	copelandScores := make(map[int]float64)
 
 	log.Printf("Starting Copeland Vote for Team %s with %d members.\n", team.TeamID, len(team.Agents))
 	// Loop through each agent in the team
@@ -456,22 +209,18 @@ func runCopelandVote(team *common.Team, cs *EnvironmentServer) []int {
 			for j := i + 1; j < len(agentAoARanking); j++ {
 				if agentAoARanking[i] < agentAoARanking[j] {
 
-					pair := []int{agentAoARanking[i], agentAoARanking[j]}
-
-					pairKey := fmt.Sprintf("%d-%d", pair[0], pair[1])
+					pair := [2]int{agentAoARanking[i], agentAoARanking[j]}
 
 					log.Printf("Agent %s: Comparing candidates %d and %d. Winner: %d\n", agent, pair[0], pair[1], pair[0])
 
-					pairwiseWins[pairKey]++
+					pairwiseWins[pair]++
 				} else {
 
-					pair := []int{agentAoARanking[j], agentAoARanking[i]}
-
-					pairKey := fmt.Sprintf("%d-%d", pair[0], pair[1])
+					pair := [2]int{agentAoARanking[j], agentAoARanking[i]}
 
 					log.Printf("Agent %s: Comparing candidates %d and %d. Winner: %d\n", agent, pair[1], pair[0], pair[1])
 
-					pairwiseWins[pairKey] -= 1
+					pairwiseWins[pair] -= 1
 				}
 
 			}
@@ -480,11 +229,10 @@ func runCopelandVote(team *common.Team, cs *EnvironmentServer) []int {
 
 	log.Println(pairwiseWins)
 	for pair, score := range pairwiseWins {
-		// Subtract ASCII value of 0
-		candidate1 := pair[0] - 48
-		candidate2 := pair[2] - 48
+		candidate1 := pair[0]
+		candidate2 := pair[1]
 
-		log.Printf("Processing pair %s (candidate 1: %d, candidate 2: %d), score: %d\n", pair, candidate1, candidate2, score)
+		log.Printf("Processing pair %v (candidate 1: %d, candidate 2: %d), score: %d\n", pair, candidate1, candidate2, score)
 
 		if score > 0 {
 			copelandScores[candidate1] += 1
@@ -504,8 +252,7 @@ func runCopelandVote(team *common.Team, cs *EnvironmentServer) []int {
 
 	var maxScore float64
 	var maxCandidates []int
-	for key, score := range copelandScores {
-		candidate := int(key)
+	for candidate, score := range copelandScores {
 		if score > maxScore {
 			maxScore = score
 			maxCandidates = []int{candidate}
@@ -514,6 +261,7 @@ func runCopelandVote(team *common.Team, cs *EnvironmentServer) []int {
 		}
 	}
 
+	sort.Ints(maxCandidates)
 	log.Printf("\nWinning candidates for Team %s: %v\n", team.TeamID, maxCandidates)
 
 	return maxCandidates
@@ -570,6 +318,7 @@ func runBordaVote(team *common.Team, aoaCandidates []int, cs *EnvironmentServer)
 	}
 
 	// Remove candidates below a threshold (check if there are ties)
+	sort.Ints(filtered)
 	log.Println("\nFiltered candidates after tie removal:")
 	log.Println(filtered)
 
@@ -577,59 +326,66 @@ func runBordaVote(team *common.Team, aoaCandidates []int, cs *EnvironmentServer)
 }
 
 func (cs *EnvironmentServer) allocateAoAs() {
-	for _, team := range cs.Teams {
-		winners := runCopelandVote(team, cs)
+	for _, teamID := range common.SortedKeys(cs.Teams) {
+		cs.allocateAoA(cs.Teams[teamID])
+	}
+}
+
+// The team votes on its AoA (unless the experiment forces one) and adopts it
+func (cs *EnvironmentServer) allocateAoA(team *common.Team) {
+	var winners []int
+	if cs.forcedAoAID != 0 {
+		// the experiment fixes the AoA, so there is nothing to vote on
+		winners = []int{cs.forcedAoAID}
+	} else {
+		cs.recordAoAVotes(team)
+		winners = runCopelandVote(team, cs)
 		if len(winners) > 1 {
 			log.Println("Multiple winners detected. Running Borda Vote.")
 			winners = runBordaVote(team, winners, cs)
 		}
-		// Select random AoA if still tied, else select 'winner'
-		if len(winners) > 0 {
-
-			// Create a random number generator with a seed based on current time
-			r := rand.New(rand.NewSource(time.Now().UnixNano()))
-			// Generate random index
-			randomI := r.Intn(len(winners))
-			preference := winners[randomI]
-
-			// Update the team's strategy
-			switch preference {
-			case 1:
-				team.TeamAoA = common.CreateTeam1AoA(team)
-				team.TeamAoAID = 1
-			case 2:
-				team.TeamAoA = common.CreateTeam2AoA(team, uuid.Nil, 5)
-				team.TeamAoAID = 2
-				cs.ElectNewLeader(team.TeamID)
-			case 3:
-				team.TeamAoA = common.CreateFixedAoA(1)
-				// TODO: Change when AoA 3 is implemented
-				team.TeamAoAID = 0
-			case 4:
-				team.TeamAoA = common.CreateTeam4AoA(team)
-				team.TeamAoAID = 4
-			case 5:
-				team.TeamAoA = common.CreateTeam5AoA()
-				team.TeamAoAID = 5
-			case 6:
-				team.TeamAoA = common.CreateTeam6AoA()
-				team.TeamAoAID = 6
-			default:
-				team.TeamAoA = common.CreateFixedAoA(1)
-				team.TeamAoAID = 0
-			}
+	}
+	// Select random AoA if still tied, else select 'winner'
+	if len(winners) > 0 {
+		// Generate random index
+		randomI := cs.random().Intn(len(winners))
+		preference := winners[randomI]
 
-			cs.Teams[team.TeamID] = team
-			log.Printf("Team %v has AoA: %v\n", team.TeamID, winners[randomI])
+		// Create the team's AoA from the registry
+		aoaID := common.AdoptAoA(preference, team, cs)
+		event := cs.newEvent(gameRecorder.AoAAdoptedEvent, uuid.Nil, team.TeamID)
+		event.AoA = &gameRecorder.AoAAdopted{AoAID: aoaID, Forced: cs.forcedAoAID != 0}
+		cs.DataRecorder.RecordEvent(event)
+
+		cs.Teams[team.TeamID] = team
+		log.Printf("Team %v has AoA: %v\n", team.TeamID, winners[randomI])
 
-		}
 	}
 }
 
-func (cs *EnvironmentServer) RunEndOfIteration(int) {
+// Record the ranking each member of the team votes with
+func (cs *EnvironmentServer) recordAoAVotes(team *common.Team) {
+	for _, agentID := range team.Agents {
+		event := cs.newEvent(gameRecorder.AoAVoteCastEvent, agentID, team.TeamID)
+		event.AoAVote = &gameRecorder.AoAVoteCast{Ranking: append([]int{}, cs.GetAgentMap()[agentID].GetAoARanking()...)}
+		cs.DataRecorder.RecordEvent(event)
+	}
+}
+
+func (cs *EnvironmentServer) RunEndOfIteration(iteration int) {
 	for _, team := range cs.Teams {
 		team.SetCommonPool(0)
 	}
+
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		cs.DataRecorder.RecordEvent(cs.newEvent(gameRecorder.IterationSurvivedEvent, agentID, cs.GetAgentMap()[agentID].GetTeamID()))
+	}
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		cs.GetAgentMap()[agentID].OnIterationEnd(iteration)
+	}
+	for _, agent := range cs.deadAgents {
+		agent.OnIterationEnd(iteration)
+	}
 }
 
 // custom override (what why this is called later then start iteration...)
@@ -644,15 +400,136 @@ func (cs *EnvironmentServer) Init(turnsForThreshold int) {
 	cs.thresholdTurns = turnsForThreshold
 }
 
+// Seed the server's random number generator. Team IDs, AoA allocation, the streams
+// handed to each AoA and (through NewAgentID) the agent IDs are all derived from
+// this seed, so two runs with the same seed and population are identical.
+func (cs *EnvironmentServer) SetSeed(seed int64) {
+	log.Printf("[server] Random seed: %v\n", seed)
+	cs.seed = seed
+	cs.rng = rand.New(rand.NewSource(seed))
+}
+
+func (cs *EnvironmentServer) GetSeed() int64 {
+	return cs.seed
+}
+
+// Set the share of a team that must vote to accept an orphan (MajorityVoteThreshold if never set)
+func (cs *EnvironmentServer) SetMajorityVoteThreshold(threshold float32) {
+	cs.majorityVoteThreshold = threshold
+}
+
+func (cs *EnvironmentServer) GetMajorityVoteThreshold() float32 {
+	if cs.majorityVoteThreshold == 0 {
+		return MajorityVoteThreshold
+	}
+	return cs.majorityVoteThreshold
+}
+
+// Make every team adopt the given AoA instead of voting on one (0 lets the teams vote)
+func (cs *EnvironmentServer) SetForcedAoA(aoaID int) {
+	cs.forcedAoAID = aoaID
+}
+
+// Set how long the server waits between team forming and the AoA vote
+func (cs *EnvironmentServer) SetTeamFormingDelay(delay time.Duration) {
+	cs.teamFormingDelay = delay
+}
+
+// Set how many teams may reject an orphan before it waits to be put in a new team with
+// other such orphans (0 lets orphans apply forever)
+func (cs *EnvironmentServer) SetMaxOrphanRejections(rejections int) {
+	cs.maxOrphanRejections = rejections
+}
+
+// Put orphans admitted into a team on probation for the given number of turns (0 for none).
+// Meanwhile they may withdraw at most withdrawalShare of an equal split of the common pool,
+// and are audited whenever their team does not vote for an audit.
+func (cs *EnvironmentServer) SetProbation(turns int, withdrawalShare float64) {
+	cs.probationTurns = turns
+	cs.probationWithdrawalShare = withdrawalShare
+}
+
+// Set how much agents remember from one iteration to the next (full memory if never set)
+func (cs *EnvironmentServer) SetMemoryPolicy(policy common.MemoryPolicy) {
+	cs.memoryPolicy = policy
+}
+
+func (cs *EnvironmentServer) GetMemoryPolicy() common.MemoryPolicy {
+	return cs.memoryPolicy
+}
+
+// Set how the threshold is chosen (nil restores the default policy)
+func (cs *EnvironmentServer) SetThresholdPolicy(policy common.IThresholdPolicy) {
+	cs.thresholdPolicy = policy
+}
+
+func (cs *EnvironmentServer) GetThresholdPolicy() common.IThresholdPolicy {
+	if cs.thresholdPolicy == nil {
+		cs.thresholdPolicy = common.DefaultThresholdPolicy()
+	}
+	return cs.thresholdPolicy
+}
+
+// A hidden threshold is chosen when it is applied, instead of at the start of the period
+func (cs *EnvironmentServer) SetThresholdHidden(hidden bool) {
+	cs.thresholdHidden = hidden
+}
+
+// Set what agents are told about the threshold. In ThresholdInfoRange the range is
+// width wide, with the threshold at a random place in it.
+func (cs *EnvironmentServer) SetThresholdInfo(mode common.ThresholdInfoMode, width int) {
+	cs.thresholdInfo = mode
+	cs.thresholdInfoRange = width
+}
+
+// Set the game agents play to generate score (nil restores the default 3d6 game)
+func (cs *EnvironmentServer) SetResourceGame(game common.IResourceGame) {
+	cs.resourceGame = game
+}
+
+func (cs *EnvironmentServer) GetResourceGame() common.IResourceGame {
+	if cs.resourceGame == nil {
+		cs.resourceGame = common.DefaultResourceGame()
+	}
+	return cs.resourceGame
+}
+
+// get the server's random number generator, seeding it from the clock if no seed was set
+func (cs *EnvironmentServer) random() *rand.Rand {
+	if cs.rng == nil {
+		cs.SetSeed(time.Now().UnixNano())
+	}
+	return cs.rng
+}
+
+// Derive a new, independent random stream from the server's generator
+func (cs *EnvironmentServer) NewRand() *rand.Rand {
+	return rand.New(rand.NewSource(cs.random().Int63()))
+}
+
+// Draw the ID of a new agent from the server's generator, so that agents created in the
+// same order get the same IDs (and therefore the same random streams) in every run with
+// the same seed
+func (cs *EnvironmentServer) NewAgentID() uuid.UUID {
+	agentID, err := uuid.NewRandomFromReader(cs.random())
+	if err != nil {
+		log.Printf("[server] Failed to generate agent ID: %v\n", err)
+		return uuid.New()
+	}
+	return agentID
+}
+
 func (cs *EnvironmentServer) reviveDeadAgents() {
 	for _, agent := range cs.deadAgents {
 		log.Printf("[server] Agent %v is being revived\n", agent.GetID())
 		agent.SetTrueScore(0) // new agents start with a score of 0
 		cs.AddAgent(agent)    // re-add the agent to the server map
+		agent.OnRevived()
 	}
 
 	// Clear the slice
 	cs.deadAgents = cs.deadAgents[:0]
+	cs.deadTeamMembers = nil
 }
 
 // debug log printing
@@ -701,16 +578,37 @@ func (cs *EnvironmentServer) LogTeamStatus() {
 func (cs *EnvironmentServer) UpdateAndGetAgentExposedInfo() []common.ExposedAgentInfo {
 	// clear the list
 	cs.agentInfoList = nil
-	for _, agent := range cs.GetAgentMap() {
-		cs.agentInfoList = append(cs.agentInfoList, agent.GetExposedInfo())
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		cs.agentInfoList = append(cs.agentInfoList, cs.exposedInfo(agentID))
 	}
 	return cs.agentInfoList
 }
 
-// create a new round score threshold
+// What the agent exposes about itself, with its reputation
+func (cs *EnvironmentServer) exposedInfo(agentID uuid.UUID) common.ExposedAgentInfo {
+	info := cs.GetAgentMap()[agentID].GetExposedInfo()
+	info.Reputation = cs.GetReputation(agentID)
+	return info
+}
+
+// The agent's public record, derived from the events recorded about it
+func (cs *EnvironmentServer) GetReputation(agentID uuid.UUID) gameRecorder.Reputation {
+	return cs.DataRecorder.GetReputation(agentID)
+}
+
+// create a new round score threshold with the threshold policy
 func (cs *EnvironmentServer) createNewRoundScoreThreshold() {
-	// NEW: increase threshold dynamically through the game
-	cs.roundScoreThreshold = rand.Intn(10) + cs.turn
+	state := common.ThresholdState{
+		Turn:        cs.turn,
+		Iteration:   cs.iteration,
+		Previous:    cs.roundScoreThreshold,
+		HasPrevious: cs.thresholdSet,
+	}
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		state.Scores = append(state.Scores, cs.GetAgentMap()[agentID].GetTrueScore())
+	}
+	cs.roundScoreThreshold = cs.GetThresholdPolicy().NextThreshold(state, cs.random())
+	cs.thresholdSet = true
 	log.Printf("[server] New round score threshold: %v\n", cs.roundScoreThreshold)
 }
 
@@ -719,14 +617,17 @@ func (cs *EnvironmentServer) killAgentBelowThreshold(agentID uuid.UUID) int {
 	agent := cs.GetAgentMap()[agentID]
 	score := agent.GetTrueScore()
 	if score < cs.roundScoreThreshold {
+		event := cs.newEvent(gameRecorder.AgentKilledEvent, agentID, agent.GetTeamID())
+		event.Killed = &gameRecorder.AgentKilled{Score: score, Threshold: cs.roundScoreThreshold}
+		cs.DataRecorder.RecordEvent(event)
 		agent.SetTrueScore(0)
-		cs.killAgent(agentID)
+		cs.killAgent(agentID, score)
 	}
 	return score
 }
 
-// kill agent
-func (cs *EnvironmentServer) killAgent(agentID uuid.UUID) {
+// kill agent, telling its teammates the score it died with
+func (cs *EnvironmentServer) killAgent(agentID uuid.UUID, score int) {
 	agent := cs.GetAgentMap()[agentID]
 
 	// Remove the agent from the team
@@ -758,6 +659,15 @@ func (cs *EnvironmentServer) killAgent(agentID uuid.UUID) {
 				cs.Teams[teamID] = team
 				// Set the team of the agent to Nil
 				agent.SetTeamID(uuid.Nil)
+				if cs.deadTeamMembers == nil {
+					cs.deadTeamMembers = make(map[uuid.UUID][]uuid.UUID)
+				}
+				cs.deadTeamMembers[teamID] = append(cs.deadTeamMembers[teamID], agentID)
+				for _, teammateID := range team.Agents {
+					if teammate, ok := cs.GetAgentMap()[teammateID]; ok {
+						teammate.OnTeammateDied(agentID, score)
+					}
+				}
 			}
 		}
 	}
@@ -767,6 +677,7 @@ func (cs *EnvironmentServer) killAgent(agentID uuid.UUID) {
 
 	// Add the agent to the dead agent list and remove it from the server's agent map
 	cs.deadAgents = append(cs.deadAgents, agent)
+	cs.turnDeaths = append(cs.turnDeaths, agentID)
 	cs.RemoveAgent(agent)
 	log.Printf("[server] Agent %v killed\n", agentID)
 }
@@ -800,7 +711,8 @@ func (cs *EnvironmentServer) StartAgentTeamForming() {
 	log.Printf("------------- [server] Starting team formation -------------\n\n")
 
 	// Launch team formation for each agent
-	for _, agent := range cs.GetAgentMap() {
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		agent := cs.GetAgentMap()[agentID]
 		agent.StartTeamForming(agent, agentInfo)
 	}
 
@@ -866,8 +778,12 @@ func (cs *EnvironmentServer) CreateAndInitTeamWithAgents(agentIDs []uuid.UUID) u
 		}
 	}
 
-	// Generate team ID first
-	teamID := uuid.New()
+	// Generate team ID first (from the server's generator, so seeded runs get the same IDs)
+	teamID, err := uuid.NewRandomFromReader(cs.random())
+	if err != nil {
+		log.Printf("[server] Failed to generate team ID: %v\n", err)
+		return uuid.UUID{}
+	}
 
 	// Protect map write with mutex
 	cs.teamsMutex.Lock()
@@ -900,11 +816,7 @@ func (cs *EnvironmentServer) GetTeamFromTeamID(teamID uuid.UUID) *common.Team {
 
 // To be used by agents to find out what teams they want to join in the next round (if they are orphaned).
 func (cs *EnvironmentServer) GetTeamIDs() []uuid.UUID {
-	teamIDs := make([]uuid.UUID, 0, len(cs.Teams))
-	for teamID := range cs.Teams {
-		teamIDs = append(teamIDs, teamID)
-	}
-	return teamIDs
+	return common.SortedKeys(cs.Teams)
 }
 
 // Can be used to find the amount in the common pool for a team. If this is used,
@@ -915,40 +827,107 @@ func (cs *EnvironmentServer) GetTeamCommonPool(teamID uuid.UUID) int {
 	return team.GetCommonPool()
 }
 
-// reset all agents (preserve memory but clears scores)
+// reset all agents (clears scores and teams, memory is kept as far as the memory policy says)
 func (cs *EnvironmentServer) ResetAgents() {
-	for _, agent := range cs.GetAgentMap() {
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		agent := cs.GetAgentMap()[agentID]
 		agent.SetTrueScore(0)
 		agent.SetTeamID(uuid.UUID{})
+		agent.ResetForIteration(cs.memoryPolicy)
 	}
 }
 
 func (cs *EnvironmentServer) ApplyThreshold() {
 	cs.thresholdAppliedInTurn = true
+	if cs.thresholdHidden {
+		cs.createNewRoundScoreThreshold()
+		cs.notifyThreshold(false)
+	}
 
-	for _, agent := range cs.GetAgentMap() {
-		cs.killAgentBelowThreshold(agent.GetID())
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		cs.killAgentBelowThreshold(agentID)
 	}
 
 	// after checking threshold, minus threshold score from each agent
-	for _, agent := range cs.GetAgentMap() {
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		agent := cs.GetAgentMap()[agentID]
 		// minus threshold score from each agent
 		agent.SetTrueScore(agent.GetTrueScore() - cs.roundScoreThreshold)
+		agent.OnThresholdApplied(common.ThresholdResult{
+			Turn:  cs.turn,
+			Paid:  cs.roundScoreThreshold,
+			Score: agent.GetTrueScore(),
+		})
 	}
 
-	cs.createNewRoundScoreThreshold() // create new threshold for the next round
+	cs.notifyThreshold(true)
+
+	if !cs.thresholdHidden {
+		cs.createNewRoundScoreThreshold() // create new threshold for the next round
+		cs.notifyThreshold(false)
+	}
+}
+
+// Tell the living agents about the threshold that has just been chosen (or applied if
+// applied is true), as far as the information mode allows
+func (cs *EnvironmentServer) notifyThreshold(applied bool) {
+	if cs.thresholdInfo == common.ThresholdInfoNone || applied != (cs.thresholdInfo == common.ThresholdInfoAfterApplied) {
+		return
+	}
+	notification := common.ThresholdNotificationMessage{
+		Mode:    cs.thresholdInfo,
+		Min:     cs.roundScoreThreshold,
+		Max:     cs.roundScoreThreshold,
+		Applied: applied,
+		Turn:    cs.turn,
+	}
+	if !applied && !cs.thresholdHidden {
+		notification.Turn = cs.nextThresholdTurn()
+	}
+	if cs.thresholdInfo == common.ThresholdInfoRange {
+		notification.Min = max(0, cs.roundScoreThreshold-cs.random().Intn(cs.thresholdInfoRange+1))
+		notification.Max = notification.Min + cs.thresholdInfoRange
+	}
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		msg := notification
+		msg.InvokeMessageHandler(cs.GetAgentMap()[agentID])
+	}
+}
+
+// The next turn in which the threshold is applied
+func (cs *EnvironmentServer) nextThresholdTurn() int {
+	turn := cs.turn + 1
+	if cs.thresholdTurns <= 0 {
+		return turn
+	}
+	for turn%cs.thresholdTurns != 0 || turn <= 1 {
+		turn++
+	}
+	return turn
 }
 
 func (cs *EnvironmentServer) RecordTurnInfo() {
+	specialNotes := eventNotes(cs.DataRecorder.EventsSinceLastTurn())
+	contributions, withdrawals := cs.turnTransactions()
+	rolls := make(map[uuid.UUID]gameRecorder.RollRecord)
+	for _, roll := range cs.turnRolls {
+		rolls[roll.AgentID] = roll
+	}
+
 	// agent information
 	agentRecords := []gameRecorder.AgentRecord{}
-	for _, agent := range cs.GetAgentMap() {
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		agent := cs.GetAgentMap()[agentID]
 		// if agent.GetTeamID() == uuid.Nil {
 		// 	// Skip agents that are not in a team
 		// 	continue
 		// }
 		newAgentRecord := agent.RecordAgentStatus(agent)
 		newAgentRecord.IsAlive = true
+		newAgentRecord.SpecialNote = specialNotes[agentID]
+		newAgentRecord.SetTransactions(contributions[agentID].Actual, contributions[agentID].Stated, withdrawals[agentID].Actual, withdrawals[agentID].Stated)
+		newAgentRecord.CumulativeDishonesty = cs.dishonesty[agentID]
+		setRollSummary(&newAgentRecord, rolls)
 		newAgentRecord.TurnNumber = cs.turn
 		newAgentRecord.IterationNumber = cs.iteration
 		agentRecords = append(agentRecords, newAgentRecord)
@@ -961,6 +940,10 @@ func (cs *EnvironmentServer) RecordTurnInfo() {
 		// }
 		newAgentRecord := agent.RecordAgentStatus(agent)
 		newAgentRecord.IsAlive = false
+		newAgentRecord.SpecialNote = specialNotes[agent.GetID()]
+		newAgentRecord.SetTransactions(contributions[agent.GetID()].Actual, contributions[agent.GetID()].Stated, withdrawals[agent.GetID()].Actual, withdrawals[agent.GetID()].Stated)
+		newAgentRecord.CumulativeDishonesty = cs.dishonesty[agent.GetID()]
+		setRollSummary(&newAgentRecord, rolls)
 		newAgentRecord.TurnNumber = cs.turn
 		newAgentRecord.IterationNumber = cs.iteration
 		agentRecords = append(agentRecords, newAgentRecord)
@@ -968,127 +951,34 @@ func (cs *EnvironmentServer) RecordTurnInfo() {
 
 	// team information
 	teamRecords := []gameRecorder.TeamRecord{}
-	for _, team := range cs.Teams {
-		newTeamRecord := gameRecorder.NewTeamRecord(team.TeamID)
+	for _, teamID := range common.SortedKeys(cs.Teams) {
+		team := cs.Teams[teamID]
+		newTeamRecord := team.RecordTeamStatus()
 		newTeamRecord.TurnNumber = cs.turn
 		newTeamRecord.IterationNumber = cs.iteration
-		newTeamRecord.TeamCommonPool = team.GetCommonPool()
+		newTeamRecord.AgentsDead = append([]uuid.UUID{}, cs.deadTeamMembers[teamID]...)
+		// teams without members skip their turn and have no flows
+		if state, ok := cs.teamTurnStates[teamID]; ok {
+			newTeamRecord.TotalContributed = state.Contributed
+			newTeamRecord.TotalWithdrawn = state.Withdrawn
+			newTeamRecord.AuditCostPaid = state.AuditCostPaid
+			newTeamRecord.PunishmentsCollected = state.PunishmentsCollected
+		}
 		teamRecords = append(teamRecords, newTeamRecord)
 	}
 
 	// common information
 	newCommonRecord := gameRecorder.NewCommonRecord(cs.turn, cs.iteration, cs.roundScoreThreshold, cs.thresholdAppliedInTurn)
-
-	cs.DataRecorder.RecordNewTurn(agentRecords, teamRecords, newCommonRecord)
-}
-
-func (cs *EnvironmentServer) RunTurnTeam5(team *common.Team) {
-	log.Println("\nRunning turn for team ", team.TeamID)
-
-	// Sum of contributions from all agents in the team for this turn
-	agentContributionsTotal := 0
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		if agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentID) {
-			continue
-		}
-		agentScore := agent.GetTrueScore()
-		expectedContribution := team.TeamAoA.GetExpectedContribution(agentID, agentScore)
-
-		// Agents make actual contribution
-		agentActualContribution := agent.GetActualContribution(agent)
-
-		// Update audit result
-		team.TeamAoA.SetContributionAuditResult(agentID, agentScore, agentActualContribution, expectedContribution)
-		agent.SetTrueScore(agentScore - agentActualContribution)
-		agentContributionsTotal += agentActualContribution
-	}
-
-	// Update common pool with total contribution from this team
-	team.SetCommonPool(team.GetCommonPool() + agentContributionsTotal)
-
-	// Initiate Contribution Audit vote
-	contributionAuditVotes := []common.Vote{}
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		vote := agent.GetContributionAuditVote()
-		contributionAuditVotes = append(contributionAuditVotes, vote)
-	}
-
-	// Execute Contribution Audit if necessary
-	if agentToAudit := team.TeamAoA.GetVoteResult(contributionAuditVotes); agentToAudit != uuid.Nil {
-		auditCost := team.TeamAoA.GetAuditCost(team.GetCommonPool())
-		if auditCost <= team.GetCommonPool() {
-			// Deduct the audit cost from the common pool
-			team.SetCommonPool(team.GetCommonPool() - auditCost)
-			log.Printf("[server] Audit cost of %v deducted from the common pool. Remaining pool: %v\n", auditCost, team.GetCommonPool())
-
-			// Proceed with the audit
-			auditResult := team.TeamAoA.GetContributionAuditResult(agentToAudit)
-			for _, agentID := range team.Agents {
-				agent := cs.GetAgentMap()[agentID]
-				agent.SetAgentContributionAuditResult(agentToAudit, auditResult)
-			}
-		} else {
-			log.Printf("[server] Not enough resources in the common pool to cover the audit cost. Skipping audit.\n")
-		}
-	}
-
-	// Calculate withdrawal order and allow agents to withdraw
-	remainingResources := team.GetCommonPool()
-	orderedAgents := team.TeamAoA.GetWithdrawalOrder(team.Agents)
-	team.TeamAoA.ResourceAllocation(cs.GetAgentScores(), remainingResources)
-	for _, agentID := range orderedAgents {
-		agent := cs.GetAgentMap()[agentID]
-		if agent.GetTeamID() == uuid.Nil || cs.IsAgentDead(agentID) {
-			continue
-		}
-
-		// Agents make actual withdrawal
-		agentActualWithdrawal := agent.GetActualWithdrawal(agent)
-		currentPool := team.GetCommonPool()
-		if agentActualWithdrawal > currentPool {
-			agentActualWithdrawal = currentPool // Ensure withdrawal does not exceed available pool
-		}
-
-		agentStatedWithdrawal := agent.GetStatedWithdrawal(agent)
-		agentScore := agent.GetTrueScore()
-
-		// Update audit result for this agent
-		team.TeamAoA.SetWithdrawalAuditResult(agentID, agentScore, agentActualWithdrawal, agentStatedWithdrawal, currentPool)
-
-		// Update agent score and common pool
-		agent.SetTrueScore(agentScore + agentActualWithdrawal)
-		team.SetCommonPool(currentPool - agentActualWithdrawal)
-		log.Printf("[server] Agent %v withdrew %v. Remaining pool: %v\n", agentID, agentActualWithdrawal, team.GetCommonPool())
-	}
-
-	// Initiate Withdrawal Audit vote
-	withdrawalAuditVotes := []common.Vote{}
-	for _, agentID := range team.Agents {
-		agent := cs.GetAgentMap()[agentID]
-		vote := agent.GetWithdrawalAuditVote()
-		withdrawalAuditVotes = append(withdrawalAuditVotes, vote)
-	}
-
-	// Execute Withdrawal Audit if necessary
-	if agentToAudit := team.TeamAoA.GetVoteResult(withdrawalAuditVotes); agentToAudit != uuid.Nil {
-		auditCost := team.TeamAoA.GetAuditCost(team.GetCommonPool())
-		if auditCost <= team.GetCommonPool() {
-			// Deduct the audit cost from the common pool
-			team.SetCommonPool(team.GetCommonPool() - auditCost)
-			log.Printf("[server] Withdrawal audit cost of %v deducted from the common pool. Remaining pool: %v\n", auditCost, team.GetCommonPool())
-
-			// Proceed with the audit
-			auditResult := team.TeamAoA.GetWithdrawalAuditResult(agentToAudit)
-			for _, agentID := range team.Agents {
-				agent := cs.GetAgentMap()[agentID]
-				agent.SetAgentWithdrawalAuditResult(agentToAudit, auditResult)
-			}
-		} else {
-			log.Printf("[server] Not enough resources in the common pool to cover the audit cost. Skipping withdrawal audit.\n")
-		}
-	}
+	policy := cs.GetThresholdPolicy()
+	newCommonRecord.ThresholdPolicy = policy.Name()
+	newCommonRecord.ThresholdParameters = policy.Parameters()
+	newCommonRecord.ThresholdHidden = cs.thresholdHidden
+	newCommonRecord.ThresholdTurns = cs.thresholdTurns
+	newCommonRecord.ThresholdInfo = cs.thresholdInfo.String()
+	newCommonRecord.MemoryRetention = cs.memoryPolicy.Retention.String()
+	newCommonRecord.MemoryDecay = cs.memoryPolicy.Decay
+
+	cs.DataRecorder.RecordNewTurn(agentRecords, teamRecords, newCommonRecord, cs.turnRolls)
 }
 
 // GetAgentScores returns the current scores of all agents in the server
@@ -1102,11 +992,18 @@ func (cs *EnvironmentServer) GetAgentScores() map[uuid.UUID]int {
 
 // In case an AoA requires agents to be kicked
 func (cs *EnvironmentServer) RemoveAgentFromTeam(agentID uuid.UUID) {
+	if teamID := cs.removeAgentFromTeam(agentID); teamID != uuid.Nil {
+		cs.DataRecorder.RecordEvent(cs.newEvent(gameRecorder.AgentKickedEvent, agentID, teamID))
+	}
+}
+
+// Take the agent out of its team, returning the ID of the team it was in (Nil if none)
+func (cs *EnvironmentServer) removeAgentFromTeam(agentID uuid.UUID) uuid.UUID {
 
 	// If the agent is already dead it can't really be kicked
 	if cs.IsAgentDead(agentID) {
 		log.Printf("[WARNING] Dead agent should not be being kicked: %s", agentID)
-		return
+		return uuid.Nil
 	}
 
 	// GetTeam() is a misleading name, but this gets the team the agent is in, as well as the agent itself
@@ -1118,17 +1015,21 @@ func (cs *EnvironmentServer) RemoveAgentFromTeam(agentID uuid.UUID) {
 	// Safety check to confirm that the team actually exists
 	if team == nil {
 		log.Printf("[WARNING] Agent being kicked does not have a team!! AgentID: %s", agentID)
-		return
+		return uuid.Nil
 	}
 
 	team.RemoveAgent(agentID)
+	return team.TeamID
 }
 
 // Ask all the agents if they want to leave the team they are in or not. Ignore dead agents
 func (cs *EnvironmentServer) ProcessAgentsLeaving() {
-	for agentID, agent := range cs.GetAgentMap() {
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		agent := cs.GetAgentMap()[agentID]
 		if !cs.IsAgentDead(agentID) && agent.GetLeaveOpinion(agentID) {
-			cs.RemoveAgentFromTeam(agentID)
+			if teamID := cs.removeAgentFromTeam(agentID); teamID != uuid.Nil {
+				cs.DataRecorder.RecordEvent(cs.newEvent(gameRecorder.AgentLeftEvent, agentID, teamID))
+			}
 		}
 	}
 }
@@ -1160,7 +1061,8 @@ func (cs *EnvironmentServer) ApplyPunishment(team *common.Team, agentToAudit uui
 
 func (cs *EnvironmentServer) GetTeamsByAoA(aoa int) []common.Team {
 	teams := make([]common.Team, 0)
-	for _, team := range cs.Teams {
+	for _, teamID := range common.SortedKeys(cs.Teams) {
+		team := cs.Teams[teamID]
 		if team.TeamAoAID == aoa {
 			teams = append(teams, *team)
 		}
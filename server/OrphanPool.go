@@ -2,18 +2,33 @@ package environmentServer
 
 import (
 	"log"
+	"slices"
+	"sort"
 
+	"github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
 	"github.com/google/uuid"
 )
 
 /* Declare the orphan pool for keeping track of agents that are not currently
-* part of a team. This maps agentID -> slice of teamIDs that agent wants to
-* join. Note that the slice of teams is processed in order, so the agent should
-* put the team it most wants to join at the start of the slice. */
-type OrphanPoolType map[uuid.UUID]struct{}
+* part of a team. This maps agentID -> what the server knows about the orphan,
+* including the slice of teamIDs that agent wants to join. Note that the slice of
+* teams is processed in order, so the agent should put the team it most wants to
+* join at the start of the slice. */
+type OrphanPoolType map[uuid.UUID]*OrphanEntry
 
-// The percentage of agents that have to vote 'accept' in order for an orphan
-// to be taken into a team
+// OrphanEntry is an orphan's place in the pool
+type OrphanEntry struct {
+	// teams the orphan applies to, most wanted first (see IExtendedAgent.GetTeamApplications)
+	Applications []uuid.UUID
+	// turns the orphan has spent in the pool
+	Waited int
+	// teams that voted against taking the orphan in, they are not asked again
+	RejectedBy []uuid.UUID
+}
+
+// The default percentage of agents that have to vote 'accept' in order for an
+// orphan to be taken into a team (see SetMajorityVoteThreshold)
 const MajorityVoteThreshold float32 = 0.7
 
 /*
@@ -21,38 +36,38 @@ const MajorityVoteThreshold float32 = 0.7
 * into the team. This function accepts a threshold, that is used to determine
 * whether to grant entry or not. For example, a threshold of 0.7 means that at
 * least 70% of agents in the team have to be willing to accept the orphan.
+* Teams whose AoA has its own admission rule (common.IAdmissionAoA) decide with
+* that rule instead, which is given the threshold as its default majority.
 *
 * There is no logic in this function to check for the case where the agent is
 * already in the team, this is not the responsibility of this function. It
 * should not happen if the orphan pool is correctly managed.
  */
 func (cs *EnvironmentServer) RequestOrphanEntry(orphanID, teamID uuid.UUID, entryThreshold float32) bool {
-	// Get the team and the current number of team members
+	return cs.DecideOrphanEntry(orphanID, teamID, entryThreshold).Admitted
+}
+
+// Collect the team's votes on the orphan and apply the team's admission rule
+func (cs *EnvironmentServer) DecideOrphanEntry(orphanID, teamID uuid.UUID, entryThreshold float32) common.AdmissionDecision {
 	team := cs.GetTeamFromTeamID(teamID)
 	agent_map := cs.GetAgentMap()
 
-	num_members := len(team.Agents)
-	total_votes := 0
-
-	// For each agent in the team
+	votes := make(map[uuid.UUID]bool, len(team.Agents))
 	for _, agentID := range team.Agents {
-		// Get their vote
-		vote := agent_map[agentID].VoteOnAgentEntry(orphanID)
-		// increment the total votes if they vote 'yes'
-		if vote {
-			total_votes++
-		}
+		votes[agentID] = agent_map[agentID].VoteOnAgentEntry(orphanID)
 	}
 
-	// Calculate the acceptance ratio and return 'yes' only if enough of the
-	// team has voted to accept.
-	acceptance := float32(total_votes) / float32(num_members)
-	return (acceptance >= entryThreshold)
+	if rule, ok := team.TeamAoA.(common.IAdmissionAoA); ok {
+		return rule.DecideAdmission(orphanID, agent_map[orphanID].GetTrueScore(), votes, entryThreshold)
+	}
+	return common.AdmissionDecision{Admitted: common.MajorityAdmission(votes, entryThreshold)}
 }
 
 /*
 * Go through the pool and attempt to allocate each of the orphans to a team,
-* based on the preference they have expressed.
+* based on the preference they have expressed. Orphans that have waited the
+* longest go first, and no team is asked twice about the same orphan. Orphans
+* rejected by maxOrphanRejections teams stop applying (see FormOrphanTeams).
  */
 func (cs *EnvironmentServer) AllocateOrphans() {
 	agent_map := cs.GetAgentMap()
@@ -63,41 +78,53 @@ func (cs *EnvironmentServer) AllocateOrphans() {
 	// iterating through it.
 	unallocated := make(OrphanPoolType)
 
+	orphans := common.SortedKeys(cs.orphanPool)
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return cs.orphanPool[orphans[i]].Waited > cs.orphanPool[orphans[j]].Waited
+	})
+
 	// for each orphan currently in the pool / shelter
-	for orphanID := range cs.orphanPool {
+	for _, orphanID := range orphans {
 		log.Printf("allocating %v\n", orphanID)
-		var accepted = false
+		entry := cs.orphanPool[orphanID]
 		var acceptedTeamID = uuid.Nil
+		var decision common.AdmissionDecision
 
-		// Check each aoa preference and try to allocate to allocate to team with that AoA
-		agent := agent_map[orphanID]
-		aoaRanking := agent.GetAoARanking()
-		if len(aoaRanking) != 0 {
-			log.Printf("orphan %v has no team preferences checking AoA ranking\n", orphanID)
-			// In preference order of AoA iterate through the teams and try to allocate
-			for _, aoa := range aoaRanking {
-				if accepted {
-					break
-				}
-				for _, team := range cs.GetTeamsByAoA(aoa) {
-					log.Printf("testing team %v\n", team.TeamID)
-					accepted = cs.RequestOrphanEntry(orphanID, team.TeamID, MajorityVoteThreshold)
-					if accepted {
-						acceptedTeamID = team.TeamID
-						break
-					}
-				}
+		for _, teamID := range cs.orphanCandidateTeams(orphanID, entry) {
+			if cs.orphanStranded(entry) {
+				break
 			}
-		} else {
-			log.Printf("orphan %v has no AoA preferences and no team preference remains in orphan pool\n", orphanID)
+			log.Printf("testing team %v\n", teamID)
+			decision = cs.DecideOrphanEntry(orphanID, teamID, cs.GetMajorityVoteThreshold())
+			if decision.Admitted {
+				acceptedTeamID = teamID
+				break
+			}
+			entry.RejectedBy = append(entry.RejectedBy, teamID)
+			event := cs.newEvent(gameRecorder.OrphanRejectedEvent, orphanID, teamID)
+			event.Orphan = &gameRecorder.OrphanPlacement{Waited: entry.Waited, Rejections: len(entry.RejectedBy)}
+			cs.DataRecorder.RecordEvent(event)
 		}
 
-		if (accepted) && (acceptedTeamID != uuid.Nil) {
+		if acceptedTeamID != uuid.Nil {
 			agent_map[orphanID].SetTeamID(acceptedTeamID) // Update agent's knowledge of its team
 			cs.AddAgentToTeam(orphanID, acceptedTeamID)   // Update team's knowledge of its agents
 			log.Printf("%v accepted by team %v !!\n", orphanID, acceptedTeamID)
+			if aoa, ok := cs.GetTeamFromTeamID(acceptedTeamID).TeamAoA.(common.IMemberAdmittedAoA); ok {
+				aoa.OnMemberAdmitted(orphanID)
+			}
+			fee := cs.chargeEntryFee(orphanID, acceptedTeamID, decision.EntryFee)
+			cs.GetTeamFromTeamID(acceptedTeamID).StartProbation(orphanID, cs.probationTurns)
+			event := cs.newEvent(gameRecorder.OrphanAllocatedEvent, orphanID, acceptedTeamID)
+			event.Orphan = &gameRecorder.OrphanPlacement{
+				Waited:     entry.Waited,
+				Rejections: len(entry.RejectedBy),
+				EntryFee:   fee,
+				Probation:  max(cs.probationTurns, 0),
+			}
+			cs.DataRecorder.RecordEvent(event)
 		} else {
-			unallocated[orphanID] = struct{}{}
+			unallocated[orphanID] = entry
 			log.Printf("%v remains in the orphan pool after allocation...\n", orphanID)
 		}
 	}
@@ -106,12 +133,184 @@ func (cs *EnvironmentServer) AllocateOrphans() {
 	cs.orphanPool = unallocated
 }
 
+// Move the entry fee (at most the orphan's score) from the orphan to the team's common pool
+func (cs *EnvironmentServer) chargeEntryFee(orphanID, teamID uuid.UUID, fee int) int {
+	orphan := cs.GetAgentMap()[orphanID]
+	fee = min(max(fee, 0), max(orphan.GetTrueScore(), 0))
+	if fee == 0 {
+		return 0
+	}
+	team := cs.GetTeamFromTeamID(teamID)
+	orphan.SetTrueScore(orphan.GetTrueScore() - fee)
+	team.SetCommonPool(team.GetCommonPool() + fee)
+	return fee
+}
+
+/*
+* Let the orphans that no existing team will take form new teams among themselves,
+* the way agents form teams at the start of an iteration: each of them (in order)
+* chooses whom to invite with DecideTeamForming and sends them a TeamFormationMessage,
+* which they accept or not. Every team formed this way votes on its AoA straight
+* away. Orphans rejected by maxOrphanRejections teams that are still alone are then
+* put in a new team by the server, once there are at least two of them.
+ */
+func (cs *EnvironmentServer) FormOrphanTeams() {
+	cs.negotiateOrphanTeams()
+	cs.formTeamFromStrandedOrphans()
+}
+
+// The orphans that have no team left to apply to, in order
+func (cs *EnvironmentServer) orphansWithoutCandidates() []uuid.UUID {
+	orphans := []uuid.UUID{}
+	for _, orphanID := range common.SortedKeys(cs.orphanPool) {
+		entry := cs.orphanPool[orphanID]
+		if cs.orphanStranded(entry) || len(cs.orphanCandidateTeams(orphanID, entry)) == 0 {
+			orphans = append(orphans, orphanID)
+		}
+	}
+	return orphans
+}
+
+func (cs *EnvironmentServer) negotiateOrphanTeams() {
+	negotiators := cs.orphansWithoutCandidates()
+	if len(negotiators) < 2 {
+		return
+	}
+	existingTeams := make(map[uuid.UUID]struct{})
+	for _, teamID := range cs.GetTeamIDs() {
+		existingTeams[teamID] = struct{}{}
+	}
+
+	for _, orphanID := range negotiators {
+		// the orphans still on their own, as they are now
+		orphanInfo := []common.ExposedAgentInfo{}
+		for _, otherID := range negotiators {
+			if cs.GetAgentMap()[otherID].GetTeamID() == uuid.Nil {
+				orphanInfo = append(orphanInfo, cs.exposedInfo(otherID))
+			}
+		}
+		orphan := cs.GetAgentMap()[orphanID]
+		orphan.SendTeamFormingInvitation(orphan.DecideTeamForming(orphanInfo))
+	}
+
+	newTeams := make(map[uuid.UUID][]uuid.UUID)
+	for _, orphanID := range negotiators {
+		teamID := cs.GetAgentMap()[orphanID].GetTeamID()
+		if teamID == uuid.Nil {
+			continue
+		}
+		if _, existed := existingTeams[teamID]; !existed {
+			newTeams[teamID] = append(newTeams[teamID], orphanID)
+		}
+	}
+	for _, teamID := range common.SortedKeys(newTeams) {
+		team := cs.GetTeamFromTeamID(teamID)
+		if team == nil {
+			continue
+		}
+		cs.allocateAoA(team)
+		cs.recordOrphanTeam(teamID, newTeams[teamID], true)
+		log.Printf("[server] Orphans %v formed the new team %v\n", newTeams[teamID], teamID)
+	}
+	// orphans that joined an existing team (through an invitation) are no longer orphans
+	for _, orphanID := range negotiators {
+		if cs.GetAgentMap()[orphanID].GetTeamID() != uuid.Nil {
+			delete(cs.orphanPool, orphanID)
+		}
+	}
+}
+
+// Record that the orphans were put in the new team and take them out of the pool
+func (cs *EnvironmentServer) recordOrphanTeam(teamID uuid.UUID, orphans []uuid.UUID, negotiated bool) {
+	for _, orphanID := range orphans {
+		entry := cs.orphanPool[orphanID]
+		event := cs.newEvent(gameRecorder.OrphanTeamFormedEvent, orphanID, teamID)
+		event.Orphan = &gameRecorder.OrphanPlacement{Waited: entry.Waited, Rejections: len(entry.RejectedBy), Negotiated: negotiated}
+		cs.DataRecorder.RecordEvent(event)
+		delete(cs.orphanPool, orphanID)
+	}
+}
+
+/*
+* The teams to ask to take in the orphan, in order: the teams it applied to, or
+* if it did not apply anywhere, the teams of each AoA in its AoA ranking. Teams
+* that have rejected it, and teams that no longer exist, are left out.
+ */
+func (cs *EnvironmentServer) orphanCandidateTeams(orphanID uuid.UUID, entry *OrphanEntry) []uuid.UUID {
+	candidates := []uuid.UUID{}
+	addCandidate := func(teamID uuid.UUID) {
+		team := cs.GetTeamFromTeamID(teamID)
+		if team == nil || len(team.Agents) == 0 || slices.Contains(entry.RejectedBy, teamID) || slices.Contains(candidates, teamID) {
+			return
+		}
+		candidates = append(candidates, teamID)
+	}
+
+	if len(entry.Applications) > 0 {
+		for _, teamID := range entry.Applications {
+			addCandidate(teamID)
+		}
+		return candidates
+	}
+
+	// Check each aoa preference and try to allocate to team with that AoA
+	aoaRanking := cs.GetAgentMap()[orphanID].GetAoARanking()
+	if len(aoaRanking) == 0 {
+		log.Printf("orphan %v has no AoA preferences and no team preference remains in orphan pool\n", orphanID)
+		return candidates
+	}
+	log.Printf("orphan %v has no team preferences checking AoA ranking\n", orphanID)
+	for _, aoa := range aoaRanking {
+		for _, team := range cs.GetTeamsByAoA(aoa) {
+			addCandidate(team.TeamID)
+		}
+	}
+	return candidates
+}
+
+// Whether the orphan has been rejected too often to keep applying (never if there is no cap)
+func (cs *EnvironmentServer) orphanStranded(entry *OrphanEntry) bool {
+	return cs.maxOrphanRejections > 0 && len(entry.RejectedBy) >= cs.maxOrphanRejections
+}
+
+// Put the orphans that stopped applying in a new team, which votes on its AoA, once
+// there are at least two of them
+func (cs *EnvironmentServer) formTeamFromStrandedOrphans() {
+	stranded := []uuid.UUID{}
+	for _, orphanID := range common.SortedKeys(cs.orphanPool) {
+		if cs.orphanStranded(cs.orphanPool[orphanID]) {
+			stranded = append(stranded, orphanID)
+		}
+	}
+	if len(stranded) < 2 {
+		return
+	}
+
+	teamID := cs.CreateAndInitTeamWithAgents(stranded)
+	if teamID == uuid.Nil {
+		return
+	}
+	cs.allocateAoA(cs.GetTeamFromTeamID(teamID))
+	cs.recordOrphanTeam(teamID, stranded, false)
+	log.Printf("[server] Orphans %v were put in the new team %v\n", stranded, teamID)
+}
+
+// The orphan's entry in the pool, if it is an orphan
+func (cs *EnvironmentServer) GetOrphanEntry(agentID uuid.UUID) (OrphanEntry, bool) {
+	entry, ok := cs.orphanPool[agentID]
+	if !ok {
+		return OrphanEntry{}, false
+	}
+	return *entry, true
+}
+
 /*
 * Go over all the agents in the agent map. If there is an agent that is not
 * part of a team, then add it to the orphan pool. This allows the server to
 * actively pick up agents that have been removed from a team or that have left.
 * This prevents agents from having to tell the server to 'please put me in the
-* orphan pool'.
+* orphan pool'. Orphans already in the pool have waited one more turn, and agents
+* that have found a team since are removed from it.
 *
 * This will not break for dead agents, because dead agents should be in a
 * separate map. (deadAgents)
@@ -124,24 +323,30 @@ func (cs *EnvironmentServer) PickUpOrphans() {
 	}
 
 	// sweep over all the agents in the server's agent map
-	for agentID, agent := range cs.GetAgentMap() {
+	for _, agentID := range common.SortedKeys(cs.GetAgentMap()) {
+		agent := cs.GetAgentMap()[agentID]
 
 		// If the agent is not part of a team
 		if agent.GetTeamID() == uuid.Nil {
 			// if the agent does not belong to a team, and is not in the orphan
 			// pool already, then add it to the orphan pool.
-			_, exists := cs.orphanPool[agentID]
+			entry, exists := cs.orphanPool[agentID]
+
+			if !exists {
+				entry = &OrphanEntry{}
+				cs.orphanPool[agentID] = entry
+				log.Printf("%v was added to the orphan pool \n", agentID)
+			} else {
+				entry.Waited++
+			}
 
 			// Extract the preferences from the agent, and update them. We do
 			// this even for orphans that are already in the pool because we want
 			// them to be able to update their preferences on which teams they
 			// would like to join
-			log.Printf("testing %v\n", agentID)
-
-			if !exists {
-				cs.orphanPool[agentID] = struct{}{}
-				log.Printf("%v was added to the orphan pool \n", agentID)
-			}
+			entry.Applications = agent.GetTeamApplications()
+		} else {
+			delete(cs.orphanPool, agentID)
 		}
 	}
 }
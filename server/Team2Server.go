@@ -2,9 +2,9 @@ package environmentServer
 
 import (
 	"log"
-	"math/rand"
 
 	"github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
 	"github.com/google/uuid"
 )
 
@@ -42,9 +42,11 @@ func (cs *EnvironmentServer) ElectNewLeader(teamId uuid.UUID) {
 			continue
 		}
 
-		// Pending fix on the main branch, this needs to call the function for any general agent
-		leaderVote := agent.Team2_GetLeaderVote()
-		votedFor := leaderVote.VotedForID
+		voter, ok := agent.(common.ILeaderElectionAgent)
+		if !ok {
+			continue
+		}
+		votedFor := voter.Team2_GetLeaderVote().VotedForID
 
 		votes[votedFor]++
 		voteCount := votes[votedFor]
@@ -62,18 +64,22 @@ func (cs *EnvironmentServer) ElectNewLeader(teamId uuid.UUID) {
 	// Handle tie by selecting randomly
 	var selectedLeader uuid.UUID
 	if len(candidates) > 1 {
-		selectedLeader = candidates[rand.Intn(len(candidates))]
+		selectedLeader = candidates[cs.random().Intn(len(candidates))]
 	} else if len(candidates) == 1 {
 		selectedLeader = candidates[0]
 	}
 
 	if len(candidates) == 0 {
 		log.Println("No candidate selected!")
-		selectedLeader = agentsInTeam[rand.Intn(len(agentsInTeam))]
+		selectedLeader = agentsInTeam[cs.random().Intn(len(agentsInTeam))]
 	}
 
-	team := cs.Teams[teamId]
-	team.TeamAoA.(*common.Team2AoA).SetLeader(selectedLeader)
+	if aoa, ok := cs.Teams[teamId].TeamAoA.(common.ILeaderElectionAoA); ok {
+		aoa.SetLeader(selectedLeader)
+		event := cs.newEvent(gameRecorder.LeaderElectedEvent, selectedLeader, teamId)
+		event.Leader = &gameRecorder.LeaderElected{Votes: votes[selectedLeader]}
+		cs.DataRecorder.RecordEvent(event)
+	}
 }
 
 /*
@@ -83,22 +89,31 @@ func (cs *EnvironmentServer) OverrideAgentRolls(agentId uuid.UUID, leaderId uuid
 	log.Printf("*****Override Agent Roll\n")
 
 	controlled := cs.GetAgentMap()[agentId]
-	leader := cs.GetAgentMap()[leaderId]
 
 	if controlled == nil {
 		log.Printf("Controlled agent with ID %v not found", agentId)
 		return
 	}
 
-	if leader == nil {
+	if cs.GetAgentMap()[leaderId] == nil {
 		log.Printf("Leader with ID %v not found", leaderId)
 		cs.ElectNewLeader(controlled.GetTeamID())
 		return
 	}
 
+	leader, ok := cs.GetAgentMap()[leaderId].(common.ILeaderElectionAgent)
+	if !ok {
+		// the leader cannot roll for others, the agent rolls for itself
+		log.Printf("Leader with ID %v cannot roll for %v", leaderId, agentId)
+		cs.RollDice(agentId)
+		return
+	}
+
+	game := cs.GetResourceGame()
 	currentScore, accumulatedScore := controlled.GetTrueScore(), 0
 	prevRoll := -1
 	rounds := 0
+	roll := gameRecorder.RollRecord{AgentID: agentId, ControlledBy: leaderId}
 
 	rollingComplete := false
 
@@ -106,6 +121,7 @@ func (cs *EnvironmentServer) OverrideAgentRolls(agentId uuid.UUID, leaderId uuid
 		log.Printf("*****Prev Roll: %d\n", prevRoll)
 		log.Printf("*****Accumulated score: %d\n", accumulatedScore)
 		stickDecision := leader.StickOrAgainFor(agentId, accumulatedScore, prevRoll)
+		roll.Decisions = append(roll.Decisions, stickDecision > 0)
 		if stickDecision > 0 {
 			log.Printf("%s decided to [STICK], score accumulated: %v", agentId, accumulatedScore)
 			break
@@ -115,11 +131,13 @@ func (cs *EnvironmentServer) OverrideAgentRolls(agentId uuid.UUID, leaderId uuid
 			log.Printf("%s decided to [CONTINUE ROLLING], previous roll: %v", agentId, prevRoll)
 		}
 
-		currentRoll := generateScore()
+		currentRoll := game.Roll(cs.random())
+		roll.Rolls = append(roll.Rolls, currentRoll)
 		log.Printf("%s rolled: %v this turn\n", agentId, currentRoll)
-		if currentRoll <= prevRoll {
+		if game.IsBust(currentRoll, prevRoll) {
 			// Gone bust, so reset the accumulated score and break out of the loop
 			accumulatedScore = 0
+			roll.Bust = true
 			log.Printf("%s **[HAS GONE BUST!]** round: %v, current score: %v\n", agentId, rounds, currentScore)
 			break
 		}
@@ -130,14 +148,9 @@ func (cs *EnvironmentServer) OverrideAgentRolls(agentId uuid.UUID, leaderId uuid
 	}
 	// In case the agent has gone bust, this does nothing
 	controlled.SetTrueScore(currentScore + accumulatedScore)
+	roll.TurnScore = accumulatedScore
+	cs.RecordRoll(roll)
+	controlled.HandleRollResult(roll)
 	// Log the updated score
 	log.Printf("%s turn score: %v, total score: %v\n", agentId, accumulatedScore, controlled.GetTrueScore())
 }
-
-func generateScore() int {
-	score := 0
-	for i := 0; i < 3; i++ {
-		score += rand.Intn(6) + 1
-	}
-	return score
-}
@@ -0,0 +1,169 @@
+package environmentServer
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	common "github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+)
+
+// Create an event in the current turn (Nil IDs if it does not concern an agent or team)
+func (cs *EnvironmentServer) newEvent(eventType gameRecorder.EventType, agentID uuid.UUID, teamID uuid.UUID) gameRecorder.EventRecord {
+	return gameRecorder.NewEventRecord(cs.turn, cs.iteration, eventType, agentID, teamID)
+}
+
+// What a phase can change. AoAs contribute, withdraw and punish in their own way, so
+// the flows are recorded from their effect on the pool and the audited agent's score.
+type phaseSnapshot struct {
+	pool int
+	// the audited agent, during the punishment phase only
+	agentID uuid.UUID
+	score   int
+}
+
+func (cs *EnvironmentServer) snapshotPhase(phase common.TurnPhase, state *common.TurnState) phaseSnapshot {
+	snapshot := phaseSnapshot{pool: state.Team.GetCommonPool()}
+	if phase != common.PunishmentPhase || state.AuditedAgent == uuid.Nil {
+		return snapshot
+	}
+	if agent, ok := cs.GetAgentMap()[state.AuditedAgent]; ok {
+		snapshot.agentID = state.AuditedAgent
+		snapshot.score = agent.GetTrueScore()
+	}
+	return snapshot
+}
+
+// Record the events and flows of a phase once it has run, whether by the default or by the AoA
+func (cs *EnvironmentServer) recordTurnPhase(phase common.TurnPhase, state *common.TurnState, before phaseSnapshot) {
+	teamID := state.Team.TeamID
+	poolDelta := state.Team.GetCommonPool() - before.pool
+	switch phase {
+	case common.ContributePhase:
+		state.Contributed += poolDelta
+
+	case common.WithdrawPhase:
+		state.Withdrawn -= poolDelta
+
+	case common.ContributionAuditVotePhase, common.WithdrawalAuditVotePhase:
+		votes, auditPhase := state.ContributionAuditVotes, common.ContributionAuditPhase
+		if phase == common.WithdrawalAuditVotePhase {
+			votes, auditPhase = state.WithdrawalAuditVotes, common.WithdrawalAuditPhase
+		}
+		for _, vote := range votes {
+			if vote.IsVote != 1 || vote.VotedForID == uuid.Nil {
+				continue
+			}
+			event := cs.newEvent(gameRecorder.AuditVoteCastEvent, vote.VoterID, teamID)
+			event.AuditVote = &gameRecorder.AuditVoteCast{Phase: auditPhase.String(), VotedForID: vote.VotedForID}
+			cs.DataRecorder.RecordEvent(event)
+		}
+
+	case common.ContributionAuditPhase, common.WithdrawalAuditPhase:
+		state.AuditCostPaid += state.AuditCost
+		if state.AuditedAgent == uuid.Nil {
+			return
+		}
+		event := cs.newEvent(gameRecorder.AuditExecutedEvent, state.AuditedAgent, teamID)
+		event.Audit = &gameRecorder.AuditExecuted{Phase: phase.String(), Caught: state.AuditResult, Cost: state.AuditCost}
+		cs.DataRecorder.RecordEvent(event)
+
+	case common.PunishmentPhase:
+		state.PunishmentsCollected += poolDelta
+		if before.agentID == uuid.Nil {
+			return
+		}
+		agent, ok := cs.GetAgentMap()[before.agentID]
+		if !ok {
+			return
+		}
+		amount := before.score - agent.GetTrueScore()
+		// caught agents are recorded even when the fine comes to nothing
+		if amount == 0 && !state.AuditResult {
+			return
+		}
+		event := cs.newEvent(gameRecorder.PunishmentAppliedEvent, before.agentID, teamID)
+		event.Punishment = &gameRecorder.PunishmentApplied{
+			Amount:      amount,
+			ScoreBefore: before.score,
+			PoolDelta:   poolDelta,
+		}
+		cs.DataRecorder.RecordEvent(event)
+	}
+}
+
+// Summarise the events that concern each agent as its SpecialNote, e.g. "AuditExecuted;PunishmentApplied".
+// Votes, information requests and surviving an iteration are left out, as nearly every agent has them.
+func eventNotes(events []gameRecorder.EventRecord) map[uuid.UUID]string {
+	notes := make(map[uuid.UUID]string)
+	for _, event := range events {
+		switch event.Type {
+		case gameRecorder.AuditVoteCastEvent, gameRecorder.AoAVoteCastEvent, gameRecorder.InformationRequestedEvent, gameRecorder.IterationSurvivedEvent:
+			continue
+		}
+		if event.AgentID == uuid.Nil {
+			continue
+		}
+		eventType := string(event.Type)
+		note := notes[event.AgentID]
+		if note == "" {
+			notes[event.AgentID] = eventType
+		} else if !slices.Contains(strings.Split(note, ";"), eventType) {
+			notes[event.AgentID] = note + ";" + eventType
+		}
+	}
+	return notes
+}
+
+// The contributions and withdrawals applied this turn, over all teams
+func (cs *EnvironmentServer) turnTransactions() (map[uuid.UUID]common.Transaction, map[uuid.UUID]common.Transaction) {
+	contributions := make(map[uuid.UUID]common.Transaction)
+	withdrawals := make(map[uuid.UUID]common.Transaction)
+	for _, state := range cs.teamTurnStates {
+		for agentID, transaction := range state.Contributions {
+			contributions[agentID] = transaction
+		}
+		for agentID, transaction := range state.Withdrawals {
+			withdrawals[agentID] = transaction
+		}
+	}
+	return contributions, withdrawals
+}
+
+// Add this turn's lies to each agent's dishonesty: overstating a contribution or
+// understating a withdrawal. Giving more or taking less than stated is not counted.
+func (cs *EnvironmentServer) updateDishonesty() {
+	if cs.dishonesty == nil {
+		cs.dishonesty = make(map[uuid.UUID]int)
+	}
+	contributions, withdrawals := cs.turnTransactions()
+	for agentID, transaction := range contributions {
+		cs.dishonesty[agentID] += max(0, transaction.Stated-transaction.Actual)
+	}
+	for agentID, transaction := range withdrawals {
+		cs.dishonesty[agentID] += max(0, transaction.Actual-transaction.Stated)
+	}
+}
+
+// Record the dice an agent rolled this turn, the turn and team are filled in by the server
+func (cs *EnvironmentServer) RecordRoll(roll gameRecorder.RollRecord) {
+	roll.TurnNumber = cs.turn
+	roll.IterationNumber = cs.iteration
+	if agent, ok := cs.GetAgentMap()[roll.AgentID]; ok {
+		roll.TeamID = agent.GetTeamID()
+	}
+	cs.turnRolls = append(cs.turnRolls, roll)
+}
+
+func setRollSummary(record *gameRecorder.AgentRecord, rolls map[uuid.UUID]gameRecorder.RollRecord) {
+	roll, ok := rolls[record.AgentID]
+	if !ok {
+		return
+	}
+	record.RollRounds = len(roll.Rolls)
+	record.RollTurnScore = roll.TurnScore
+	record.Bust = roll.Bust
+	record.RollControlled = roll.ControlledBy != uuid.Nil
+}
@@ -0,0 +1,340 @@
+package environmentServer
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+
+	common "github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+)
+
+// RunTeamTurn runs every phase of the turn for the team, in the order of common.TurnPhases.
+// The team's AoA may override phases and hook into them, see common.TurnPhase.
+func (cs *EnvironmentServer) RunTeamTurn(team *common.Team) {
+	log.Println("\nRunning turn for team ", team.TeamID)
+
+	state := &common.TurnState{Team: team}
+	if cs.teamTurnStates == nil {
+		cs.teamTurnStates = make(map[uuid.UUID]*common.TurnState)
+	}
+	cs.teamTurnStates[team.TeamID] = state
+	for _, phase := range common.TurnPhases {
+		if phase == common.ContributionAuditPhase || phase == common.WithdrawalAuditPhase {
+			// do not let the result of the previous audit leak into this one
+			state.AuditPhase = phase
+			state.AuditedAgent = uuid.Nil
+			state.AuditResult = false
+			state.AuditCost = 0
+		}
+		before := cs.snapshotPhase(phase, state)
+
+		if hook, ok := team.TeamAoA.(common.IBeforeTurnPhaseHook); ok {
+			hook.BeforeTurnPhase(phase, cs, state)
+		}
+
+		runPhase := cs.RunDefaultTurnPhase
+		if overrides, ok := team.TeamAoA.(common.ITurnPhaseOverrides); ok {
+			if override, ok := overrides.OverrideTurnPhase(phase); ok {
+				runPhase = func(_ common.TurnPhase, state *common.TurnState) { override(cs, state) }
+			}
+		}
+		runPhase(phase, state)
+		cs.recordTurnPhase(phase, state, before)
+
+		if hook, ok := team.TeamAoA.(common.IAfterTurnPhaseHook); ok {
+			hook.AfterTurnPhase(phase, cs, state)
+		}
+	}
+}
+
+// RunDefaultTurnPhase runs the phase as it is run for AoAs that do not override it
+func (cs *EnvironmentServer) RunDefaultTurnPhase(phase common.TurnPhase, state *common.TurnState) {
+	switch phase {
+	case common.RollPhase:
+		cs.runRollPhase(state)
+	case common.ContributePhase:
+		cs.runContributePhase(state)
+	case common.ContributionAuditVotePhase:
+		state.ContributionAuditVotes = cs.collectAuditVotes(state.Team, common.IExtendedAgent.GetContributionAuditVote)
+	case common.ContributionAuditPhase:
+		cs.runContributionAuditPhase(state)
+	case common.WithdrawalProposalPhase:
+		cs.runWithdrawalProposalPhase(state)
+	case common.WithdrawPhase:
+		cs.runWithdrawPhase(state)
+	case common.StateWithdrawalPhase:
+		cs.runStateWithdrawalPhase(state)
+	case common.WithdrawalAuditVotePhase:
+		state.WithdrawalAuditVotes = cs.collectAuditVotes(state.Team, common.IExtendedAgent.GetWithdrawalAuditVote)
+	case common.WithdrawalAuditPhase:
+		cs.runWithdrawalAuditPhase(state)
+	case common.PunishmentPhase:
+		if state.AuditedAgent != uuid.Nil && state.AuditResult {
+			cs.ApplyPunishment(state.Team, state.AuditedAgent)
+		}
+	default:
+		log.Printf("[server] Unknown turn phase %v\n", phase)
+	}
+}
+
+func (cs *EnvironmentServer) runRollPhase(state *common.TurnState) {
+	for _, agentID := range state.Team.Agents {
+		if !common.IsActiveAgent(cs, agentID) {
+			continue
+		}
+		cs.RollDice(agentID)
+	}
+}
+
+// Roll the dice for an agent until it sticks or goes bust, asking it whether to stick
+// after every roll, and add the turn score to its true score. The dice are rolled by
+// the server so that agents can not award themselves points.
+func (cs *EnvironmentServer) RollDice(agentID uuid.UUID) {
+	agent, ok := cs.GetAgentMap()[agentID]
+	if !ok {
+		log.Printf("[server] Agent %v not found, can not roll the dice\n", agentID)
+		return
+	}
+
+	game := cs.GetResourceGame()
+	prevRoll, turnScore := -1, 0
+	roll := gameRecorder.RollRecord{AgentID: agentID}
+	for {
+		currentRoll := game.Roll(cs.random())
+		roll.Rolls = append(roll.Rolls, currentRoll)
+		if game.IsBust(currentRoll, prevRoll) {
+			// lose all turn score
+			turnScore = 0
+			roll.Bust = true
+			break
+		}
+
+		turnScore += currentRoll
+		prevRoll = currentRoll
+		willStick := agent.StickOrAgain(turnScore, currentRoll)
+		roll.Decisions = append(roll.Decisions, willStick)
+		if willStick {
+			agent.DecideStick()
+			break
+		}
+		agent.DecideRollAgain()
+	}
+
+	agent.SetTrueScore(agent.GetTrueScore() + turnScore)
+	roll.TurnScore = turnScore
+	cs.RecordRoll(roll)
+	agent.HandleRollResult(roll)
+}
+
+func (cs *EnvironmentServer) runContributePhase(state *common.TurnState) {
+	team := state.Team
+	// Sum of contributions from all agents in the team for this turn
+	agentContributionsTotal := 0
+	for _, agentID := range team.Agents {
+		if !common.IsActiveAgent(cs, agentID) {
+			continue
+		}
+		agent := cs.GetAgentMap()[agentID]
+
+		agentActualContribution := agent.GetActualContribution(agent)
+		agentContributionsTotal += agentActualContribution
+		agentStatedContribution := agent.GetStatedContribution(agent)
+
+		agent.StateContributionToTeam(agent)
+		agentScore := agent.GetTrueScore()
+		// Update audit result for this agent
+		team.TeamAoA.SetContributionAuditResult(agentID, agentScore, agentActualContribution, agentStatedContribution)
+		agent.SetTrueScore(agentScore - agentActualContribution)
+		state.RecordContribution(agentID, agentActualContribution, agentStatedContribution)
+	}
+
+	// Update common pool with total contribution from this team
+	// 	Agents do not get to see the common pool before deciding their contribution
+	//  Different to the withdrawal phase!
+	team.SetCommonPool(team.GetCommonPool() + agentContributionsTotal)
+
+	// Agents vote on each other's rank once the contributions are in
+	if aoa, ok := team.TeamAoA.(common.IRankedWithdrawalAoA); ok {
+		rankUpVoteMap := make(map[uuid.UUID]map[uuid.UUID]int)
+		for _, agentID := range team.Agents {
+			if voter, ok := cs.GetAgentMap()[agentID].(common.IRankedWithdrawalAgent); ok {
+				rankUpVoteMap[agentID] = voter.Team4_GetRankUpVote()
+			}
+		}
+		aoa.Team4_SetRankUp(rankUpVoteMap)
+	}
+}
+
+func (cs *EnvironmentServer) collectAuditVotes(team *common.Team, getVote func(common.IExtendedAgent) common.Vote) []common.Vote {
+	votes := []common.Vote{}
+	for _, agentID := range team.Agents {
+		agent := cs.GetAgentMap()[agentID]
+		votes = append(votes, getVote(agent))
+	}
+	return votes
+}
+
+// Execute Contribution Audit if necessary
+func (cs *EnvironmentServer) runContributionAuditPhase(state *common.TurnState) {
+	team := state.Team
+	state.AuditPhase = common.ContributionAuditPhase
+	agentToAudit := state.AuditedAgent
+	if agentToAudit == uuid.Nil {
+		agentToAudit = cs.AgentToAudit(state)
+	}
+	if agentToAudit == uuid.Nil {
+		return
+	}
+
+	auditResult := team.TeamAoA.GetContributionAuditResult(agentToAudit)
+	state.AuditedAgent, state.AuditResult = agentToAudit, auditResult
+	for _, agentID := range team.Agents {
+		agent := cs.GetAgentMap()[agentID]
+		agent.SetAgentContributionAuditResult(agentToAudit, auditResult)
+	}
+}
+
+// Nothing happens before withdrawals unless the AoA votes on withdrawal proposals or allocates the pool
+func (cs *EnvironmentServer) runWithdrawalProposalPhase(state *common.TurnState) {
+	team := state.Team
+	if aoa, ok := team.TeamAoA.(common.IRankedWithdrawalAoA); ok {
+		proposedWithdrawalMap := make(map[uuid.UUID]int)
+		withdrawalVoteMap := make(map[uuid.UUID]map[uuid.UUID]int)
+		for _, agentID := range team.Agents {
+			if proposer, ok := cs.GetAgentMap()[agentID].(common.IRankedWithdrawalAgent); ok {
+				proposedWithdrawalMap[agentID] = proposer.Team4_GetProposedWithdrawal(cs.GetAgentMap()[agentID])
+				proposer.Team4_StateProposalToTeam()
+			}
+		}
+		for _, agentID := range team.Agents {
+			if voter, ok := cs.GetAgentMap()[agentID].(common.IRankedWithdrawalAgent); ok {
+				// Get Map of AgentId and 1 or 0 to proposed withdrawal (for each agent)
+				withdrawalVoteMap[agentID] = voter.Team4_GetProposedWithdrawalVote()
+			}
+		}
+		aoa.Team4_RunProposedWithdrawalVote(proposedWithdrawalMap, withdrawalVoteMap)
+	}
+
+	if aoa, ok := team.TeamAoA.(common.IResourceAllocationAoA); ok {
+		aoa.ResourceAllocation(cs.GetAgentScores(), team.GetCommonPool())
+	}
+}
+
+func (cs *EnvironmentServer) runWithdrawPhase(state *common.TurnState) {
+	team := state.Team
+	probationLimit := cs.probationWithdrawalLimit(team)
+	orderedAgents := team.TeamAoA.GetWithdrawalOrder(team.Agents)
+	for _, agentID := range orderedAgents {
+		if !common.IsActiveAgent(cs, agentID) {
+			continue
+		}
+		agent := cs.GetAgentMap()[agentID]
+
+		// Pass the current pool value to agent's methods
+		currentPool := team.GetCommonPool()
+		agentActualWithdrawal := agent.GetActualWithdrawal(agent)
+		if agentActualWithdrawal > currentPool {
+			agentActualWithdrawal = currentPool // Ensure withdrawal does not exceed available pool
+		}
+		if team.IsOnProbation(agentID) && agentActualWithdrawal > probationLimit {
+			agentActualWithdrawal = probationLimit
+		}
+		agentStatedWithdrawal := agent.GetStatedWithdrawal(agent)
+
+		agentScore := agent.GetTrueScore()
+		// Update audit result for this agent
+		team.TeamAoA.SetWithdrawalAuditResult(agentID, agentScore, agentActualWithdrawal, agentStatedWithdrawal, team.GetCommonPool())
+		agent.SetTrueScore(agentScore + agentActualWithdrawal)
+		state.RecordWithdrawal(agentID, agentActualWithdrawal, agentStatedWithdrawal)
+
+		// Update the common pool after each withdrawal so agents can see the updated pool before deciding their withdrawal.
+		//  Different to the contribution phase!
+		team.SetCommonPool(currentPool - agentActualWithdrawal)
+		log.Printf("[server] Agent %v withdrew %v. Remaining pool: %v\n", agentID, agentActualWithdrawal, team.GetCommonPool())
+	}
+}
+
+func (cs *EnvironmentServer) runStateWithdrawalPhase(state *common.TurnState) {
+	stateWithdrawOrder := make([]uuid.UUID, len(state.Team.Agents))
+	copy(stateWithdrawOrder, state.Team.Agents)
+	// Shuffle the order of agents to broadcast withdrawal amounts
+	cs.random().Shuffle(len(stateWithdrawOrder), func(i, j int) {
+		stateWithdrawOrder[i], stateWithdrawOrder[j] = stateWithdrawOrder[j], stateWithdrawOrder[i]
+	})
+
+	for _, agentID := range stateWithdrawOrder {
+		if !common.IsActiveAgent(cs, agentID) {
+			continue
+		}
+		agent := cs.GetAgentMap()[agentID]
+		agent.StateWithdrawalToTeam(agent)
+	}
+}
+
+// Execute Withdrawal Audit if necessary
+func (cs *EnvironmentServer) runWithdrawalAuditPhase(state *common.TurnState) {
+	team := state.Team
+	state.AuditPhase = common.WithdrawalAuditPhase
+	agentToAudit := state.AuditedAgent
+	if agentToAudit == uuid.Nil {
+		agentToAudit = cs.AgentToAudit(state)
+	}
+	if agentToAudit == uuid.Nil {
+		return
+	}
+
+	auditResult := team.TeamAoA.GetWithdrawalAuditResult(agentToAudit)
+	state.AuditedAgent, state.AuditResult = agentToAudit, auditResult
+	for _, agentID := range team.Agents {
+		agent := cs.GetAgentMap()[agentID]
+		agent.SetAgentWithdrawalAuditResult(agentToAudit, auditResult)
+	}
+}
+
+// AgentToAudit is the agent the team voted to audit in the current audit phase, or the member
+// on probation to audit if the team voted for no one
+func (cs *EnvironmentServer) AgentToAudit(state *common.TurnState) uuid.UUID {
+	votes := state.ContributionAuditVotes
+	if state.AuditPhase == common.WithdrawalAuditPhase {
+		votes = state.WithdrawalAuditVotes
+	}
+	if agentToAudit := state.Team.TeamAoA.GetVoteResult(votes); agentToAudit != uuid.Nil {
+		return agentToAudit
+	}
+	return cs.probationerToAudit(state.Team)
+}
+
+// The most a member on probation may withdraw this turn: its share of an equal split of the pool
+func (cs *EnvironmentServer) probationWithdrawalLimit(team *common.Team) int {
+	if len(team.Agents) == 0 {
+		return 0
+	}
+	equalShare := float64(team.GetCommonPool()) / float64(len(team.Agents))
+	return int(equalShare * cs.probationWithdrawalShare)
+}
+
+// The member on probation audited when the team votes for no one: the newest one (most
+// turns left), or nobody if no member is on probation
+func (cs *EnvironmentServer) probationerToAudit(team *common.Team) uuid.UUID {
+	probationers := team.GetProbationers()
+	chosen := uuid.Nil
+	for _, agentID := range common.SortedKeys(probationers) {
+		if !common.IsActiveAgent(cs, agentID) {
+			continue
+		}
+		if chosen == uuid.Nil || probationers[agentID] > probationers[chosen] {
+			chosen = agentID
+		}
+	}
+	return chosen
+}
+
+// Count the turn towards every probation, and record the members who became full members
+func (cs *EnvironmentServer) serveProbation() {
+	for _, teamID := range common.SortedKeys(cs.Teams) {
+		for _, agentID := range cs.Teams[teamID].ServeProbation() {
+			cs.DataRecorder.RecordEvent(cs.newEvent(gameRecorder.ProbationEndedEvent, agentID, teamID))
+		}
+	}
+}
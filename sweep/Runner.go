@@ -0,0 +1,184 @@
+package sweep
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"sync"
+
+	config "github.com/ADimoska/SOMASExtended/config"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+	envServer "github.com/ADimoska/SOMASExtended/server"
+)
+
+// RunResult holds the metrics of a single simulation, averaged over its iterations
+type RunResult struct {
+	SurvivalRate   float64
+	MeanFinalScore float64
+	Gini           float64
+	// share of the teams of the last iteration that adopted each AoA
+	AoAAdoption map[int]float64
+}
+
+// CellResult holds the metrics of a cell, averaged over its seeds
+type CellResult struct {
+	Cell Cell
+	Runs int
+	RunResult
+}
+
+type job struct {
+	cell  int
+	seed  int64
+	scene *config.SimulationConfig
+}
+
+// Run runs every cell of the sweep once per seed and returns one result per cell, in grid order.
+// Simulations run in parallel on fresh servers; their logs are discarded.
+func Run(sweepConfig *SweepConfig) ([]CellResult, error) {
+	base, err := sweepConfig.BaseScenario()
+	if err != nil {
+		return nil, err
+	}
+	return RunFrom(sweepConfig, base)
+}
+
+// RunFrom runs the sweep starting from the given base scenario instead of sweepConfig.Base
+func RunFrom(sweepConfig *SweepConfig, base *config.SimulationConfig) ([]CellResult, error) {
+	cells := sweepConfig.Grid.Cells(base)
+
+	jobs := []job{}
+	for i, cell := range cells {
+		for s := 0; s < sweepConfig.Seeds; s++ {
+			seed := sweepConfig.FirstSeed + int64(s)
+			scene := cell.Scenario(base, seed)
+			if err := scene.Validate(); err != nil {
+				return nil, fmt.Errorf("cell %s: %v", cell, err)
+			}
+			jobs = append(jobs, job{cell: i, seed: seed, scene: scene})
+		}
+	}
+
+	workers := sweepConfig.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// the servers log through the global logger, which would interleave across runs
+	previousOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(previousOutput)
+
+	runResults := make([]RunResult, len(jobs))
+	runErrors := make([]error, len(jobs))
+	jobIndices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobIndices {
+				runResults[i], runErrors[i] = runOnce(jobs[i].scene)
+			}
+		}()
+	}
+	for i := range jobs {
+		jobIndices <- i
+	}
+	close(jobIndices)
+	wg.Wait()
+	for i, err := range runErrors {
+		if err != nil {
+			return nil, fmt.Errorf("cell %s, seed %d: %v", cells[jobs[i].cell], jobs[i].seed, err)
+		}
+	}
+
+	cellResults := make([]CellResult, len(cells))
+	for i, cell := range cells {
+		cellResults[i] = CellResult{Cell: cell, RunResult: RunResult{AoAAdoption: make(map[int]float64)}}
+	}
+	for i, result := range runResults {
+		cellResult := &cellResults[jobs[i].cell]
+		cellResult.Runs++
+		cellResult.SurvivalRate += result.SurvivalRate
+		cellResult.MeanFinalScore += result.MeanFinalScore
+		cellResult.Gini += result.Gini
+		for aoa, share := range result.AoAAdoption {
+			cellResult.AoAAdoption[aoa] += share
+		}
+	}
+	for i := range cellResults {
+		cellResult := &cellResults[i]
+		runs := float64(cellResult.Runs)
+		cellResult.SurvivalRate /= runs
+		cellResult.MeanFinalScore /= runs
+		cellResult.Gini /= runs
+		for aoa := range cellResult.AoAAdoption {
+			cellResult.AoAAdoption[aoa] /= runs
+		}
+	}
+	return cellResults, nil
+}
+
+// runOnce builds and runs one simulation and measures it
+func runOnce(scene *config.SimulationConfig) (RunResult, error) {
+	serv, err := config.BuildSimulation(scene)
+	if err != nil {
+		return RunResult{}, err
+	}
+	serv.Start()
+
+	result := Measure(serv.DataRecorder.TurnRecords)
+	result.AoAAdoption = aoaAdoption(serv)
+	return result, nil
+}
+
+// Measure computes the survival rate, mean final score and Gini coefficient of the final
+// scores from the last turn of each iteration, averaged over the iterations.
+func Measure(turnRecords []gameRecorder.TurnRecord) RunResult {
+	// the last record of each iteration holds the final state of that iteration
+	lastTurns := []gameRecorder.TurnRecord{}
+	for i, record := range turnRecords {
+		if i+1 == len(turnRecords) || turnRecords[i+1].CommonRecord.IterationNumber != record.CommonRecord.IterationNumber {
+			lastTurns = append(lastTurns, record)
+		}
+	}
+
+	result := RunResult{}
+	if len(lastTurns) == 0 {
+		return result
+	}
+	for _, record := range lastTurns {
+		alive := 0
+		scores := []float64{}
+		for _, agentRecord := range record.AgentRecords {
+			if agentRecord.IsAlive {
+				alive++
+			}
+			scores = append(scores, float64(agentRecord.Score))
+		}
+		if len(scores) == 0 {
+			continue
+		}
+		result.SurvivalRate += float64(alive) / float64(len(scores))
+		result.MeanFinalScore += mean(scores)
+		result.Gini += Gini(scores)
+	}
+	iterations := float64(len(lastTurns))
+	result.SurvivalRate /= iterations
+	result.MeanFinalScore /= iterations
+	result.Gini /= iterations
+	return result
+}
+
+func aoaAdoption(serv *envServer.EnvironmentServer) map[int]float64 {
+	adoption := make(map[int]float64)
+	if len(serv.Teams) == 0 {
+		return adoption
+	}
+	for _, team := range serv.Teams {
+		adoption[team.TeamAoAID] += 1 / float64(len(serv.Teams))
+	}
+	return adoption
+}
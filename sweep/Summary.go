@@ -0,0 +1,106 @@
+package sweep
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Gini returns the Gini coefficient of the values (0 when all values are equal, close to 1
+// when one value holds everything). Negative values are treated as 0.
+func Gini(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	for i, value := range values {
+		if value > 0 {
+			sorted[i] = value
+		}
+	}
+	sort.Float64s(sorted)
+
+	total := 0.0
+	weighted := 0.0
+	for i, value := range sorted {
+		total += value
+		weighted += float64(i+1) * value
+	}
+	if total == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return (2*weighted)/(n*total) - (n+1)/n
+}
+
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
+
+// WriteSummary writes one row per cell: the grid values followed by the averaged metrics
+// and one adoption column per AoA
+func WriteSummary(w io.Writer, results []CellResult) error {
+	aoaIDs := []int{}
+	seen := make(map[int]bool)
+	for _, result := range results {
+		for aoa := range result.AoAAdoption {
+			if !seen[aoa] {
+				seen[aoa] = true
+				aoaIDs = append(aoaIDs, aoa)
+			}
+		}
+	}
+	sort.Ints(aoaIDs)
+
+	writer := csv.NewWriter(w)
+	header := []string{"Agents", "ThresholdTurns", "MajorityVoteThreshold", "ForcedAoA", "CheaterRatio",
+		"Runs", "SurvivalRate", "MeanFinalScore", "Gini"}
+	for _, aoa := range aoaIDs {
+		header = append(header, fmt.Sprintf("AoA%dAdoption", aoa))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		row := []string{
+			result.Cell.agentsLabel(),
+			fmt.Sprint(result.Cell.ThresholdTurns),
+			fmt.Sprint(result.Cell.MajorityVoteThreshold),
+			fmt.Sprint(result.Cell.ForcedAoA),
+			result.Cell.cheaterLabel(),
+			fmt.Sprint(result.Runs),
+			fmt.Sprintf("%.4f", result.SurvivalRate),
+			fmt.Sprintf("%.4f", result.MeanFinalScore),
+			fmt.Sprintf("%.4f", result.Gini),
+		}
+		for _, aoa := range aoaIDs {
+			row = append(row, fmt.Sprintf("%.4f", result.AoAAdoption[aoa]))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteSummaryFile writes the summary table to a CSV file, creating its directory if needed
+func WriteSummaryFile(path string, results []CellResult) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create summary file: %v", err)
+	}
+	defer file.Close()
+	return WriteSummary(file, results)
+}
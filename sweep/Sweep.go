@@ -0,0 +1,227 @@
+package sweep
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	config "github.com/ADimoska/SOMASExtended/config"
+)
+
+// SweepConfig describes a parameter sweep: every combination of the grid values
+// is run once per seed, starting from the base scenario.
+type SweepConfig struct {
+	// path to the base scenario (the built-in default scenario when empty)
+	Base string `yaml:"base"`
+	// number of seeds per cell, seeds are FirstSeed, FirstSeed+1, ...
+	Seeds     int   `yaml:"seeds"`
+	FirstSeed int64 `yaml:"firstSeed"`
+	// number of simulations run in parallel (number of CPUs when 0)
+	Workers int `yaml:"workers"`
+	// path of the CSV summary table
+	Output string `yaml:"output"`
+	Grid   Grid   `yaml:"grid"`
+}
+
+// Grid lists the values swept for each parameter. An empty list keeps the value of the base scenario.
+type Grid struct {
+	// number of agents per agent constructor, e.g. {Team1: 10, Team2: 10}
+	AgentsPerTeam          []map[string]int `yaml:"agentsPerTeam"`
+	ThresholdTurns         []int            `yaml:"thresholdTurns"`
+	MajorityVoteThresholds []float32        `yaml:"majorityVoteThresholds"`
+	// 0 lets the teams vote on their AoA
+	ForcedAoAs []int `yaml:"forcedAoAs"`
+	// share of the Team1 agents that cheat (split between short and long term cheaters)
+	CheaterRatios []float64 `yaml:"cheaterRatios"`
+}
+
+// Cell is one combination of grid values
+type Cell struct {
+	AgentsPerTeam         map[string]int // nil keeps the base population
+	ThresholdTurns        int
+	MajorityVoteThreshold float32
+	ForcedAoA             int
+	CheaterRatio          float64 // negative keeps the base Team1 agent types
+}
+
+// LoadSweepConfig reads a YAML or JSON sweep file
+func LoadSweepConfig(path string) (*SweepConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sweep file: %v", err)
+	}
+	sweepConfig := &SweepConfig{}
+	if err := yaml.Unmarshal(data, sweepConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse sweep file: %v", err)
+	}
+	if sweepConfig.Seeds <= 0 {
+		return nil, fmt.Errorf("seeds must be positive, got %d", sweepConfig.Seeds)
+	}
+	for _, ratio := range sweepConfig.Grid.CheaterRatios {
+		if ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("cheater ratios must be between 0 and 1, got %v", ratio)
+		}
+	}
+	return sweepConfig, nil
+}
+
+// BaseScenario loads the scenario the sweep starts from
+func (sc *SweepConfig) BaseScenario() (*config.SimulationConfig, error) {
+	if sc.Base == "" {
+		return config.DefaultConfig(), nil
+	}
+	return config.LoadConfig(sc.Base)
+}
+
+// Cells expands the grid into all combinations of its values
+func (g Grid) Cells(base *config.SimulationConfig) []Cell {
+	agentsPerTeam := g.AgentsPerTeam
+	if len(agentsPerTeam) == 0 {
+		agentsPerTeam = []map[string]int{nil}
+	}
+	thresholdTurns := g.ThresholdTurns
+	if len(thresholdTurns) == 0 {
+		thresholdTurns = []int{base.Server.ThresholdTurns}
+	}
+	majorityVoteThresholds := g.MajorityVoteThresholds
+	if len(majorityVoteThresholds) == 0 {
+		majorityVoteThresholds = []float32{base.Server.MajorityVoteThreshold}
+	}
+	forcedAoAs := g.ForcedAoAs
+	if len(forcedAoAs) == 0 {
+		forcedAoAs = []int{base.Server.ForcedAoA}
+	}
+	cheaterRatios := g.CheaterRatios
+	if len(cheaterRatios) == 0 {
+		cheaterRatios = []float64{-1}
+	}
+
+	cells := []Cell{}
+	for _, agents := range agentsPerTeam {
+		for _, turns := range thresholdTurns {
+			for _, majority := range majorityVoteThresholds {
+				for _, aoa := range forcedAoAs {
+					for _, ratio := range cheaterRatios {
+						cells = append(cells, Cell{
+							AgentsPerTeam:         agents,
+							ThresholdTurns:        turns,
+							MajorityVoteThreshold: majority,
+							ForcedAoA:             aoa,
+							CheaterRatio:          ratio,
+						})
+					}
+				}
+			}
+		}
+	}
+	return cells
+}
+
+// Scenario returns a copy of the base scenario with the cell's values applied
+func (c Cell) Scenario(base *config.SimulationConfig, seed int64) *config.SimulationConfig {
+	scenario := *base
+	scenario.Server.Seed = seed
+	scenario.Server.ThresholdTurns = c.ThresholdTurns
+	scenario.Server.MajorityVoteThreshold = c.MajorityVoteThreshold
+	scenario.Server.ForcedAoA = c.ForcedAoA
+	// the pause only exists for interactive runs
+	scenario.Server.TeamFormingDelay = 0
+
+	if c.AgentsPerTeam == nil {
+		scenario.Population = []config.PopulationEntry{}
+		team1Count := 0
+		for _, entry := range base.Population {
+			if entry.Agent == "Team1" && c.CheaterRatio >= 0 {
+				team1Count += entry.Count
+				continue
+			}
+			scenario.Population = append(scenario.Population, entry)
+		}
+		if team1Count > 0 {
+			scenario.Population = append(scenario.Population, c.team1Entries(baseEntry(base, "Team1"), team1Count)...)
+		}
+		return &scenario
+	}
+
+	scenario.Population = []config.PopulationEntry{}
+	for _, name := range sortedNames(c.AgentsPerTeam) {
+		count := c.AgentsPerTeam[name]
+		if count <= 0 {
+			continue
+		}
+		entry := baseEntry(base, name)
+		if name == "Team1" && c.CheaterRatio >= 0 {
+			scenario.Population = append(scenario.Population, c.team1Entries(entry, count)...)
+			continue
+		}
+		entry.Count = count
+		scenario.Population = append(scenario.Population, entry)
+	}
+	return &scenario
+}
+
+// The first entry of the base population created with the constructor, so a cell keeps its
+// settings (initial score, AoA ranking...), or a bare entry if the base has none
+func baseEntry(base *config.SimulationConfig, name string) config.PopulationEntry {
+	for _, entry := range base.Population {
+		if entry.Agent == name {
+			return entry
+		}
+	}
+	return config.PopulationEntry{Agent: name}
+}
+
+// Split count Team1 agents into honest agents and (alternately) short and long term cheaters
+func (c Cell) team1Entries(entry config.PopulationEntry, count int) []config.PopulationEntry {
+	cheaters := int(math.Round(c.CheaterRatio * float64(count)))
+	types := map[string]int{
+		"Honest":         count - cheaters,
+		"CheatShortTerm": (cheaters + 1) / 2,
+		"CheatLongTerm":  cheaters / 2,
+	}
+	entries := []config.PopulationEntry{}
+	for _, agentType := range []string{"Honest", "CheatShortTerm", "CheatLongTerm"} {
+		if types[agentType] > 0 {
+			entry.Count, entry.AgentType = types[agentType], agentType
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// String describes the cell in a single line
+func (c Cell) String() string {
+	return fmt.Sprintf("agents[%s] thresholdTurns=%d majority=%v forcedAoA=%d cheaters=%s",
+		c.agentsLabel(), c.ThresholdTurns, c.MajorityVoteThreshold, c.ForcedAoA, c.cheaterLabel())
+}
+
+func (c Cell) agentsLabel() string {
+	if c.AgentsPerTeam == nil {
+		return "base"
+	}
+	parts := []string{}
+	for _, name := range sortedNames(c.AgentsPerTeam) {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, c.AgentsPerTeam[name]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c Cell) cheaterLabel() string {
+	if c.CheaterRatio < 0 {
+		return "base"
+	}
+	return fmt.Sprint(c.CheaterRatio)
+}
+
+func sortedNames(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1 +1,40 @@
 package main
+
+import (
+	"testing"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Votes for the leader count double, so the leader and another agent can both have a majority:
+// the one with the most votes is audited, whatever order the votes are counted in
+func TestTeam2VoteResultMostVotes(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	common.SortUUIDs(agentIDs)
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(agentIDs[:5]))
+	leader, suspect := agentIDs[4], agentIDs[0]
+
+	votes := []common.Vote{}
+	for i, voterID := range team.Agents {
+		votedFor := suspect
+		if i < 2 {
+			votedFor = leader
+		}
+		votes = append(votes, common.CreateVote(1, voterID, votedFor))
+	}
+	for i := 0; i < 20; i++ {
+		aoa := common.CreateTeam2AoA(team, leader, 5, serv.NewRand())
+		assert.Equal(t, leader, aoa.GetVoteResult(votes))
+	}
+
+	// without a majority nobody is audited
+	aoa := common.CreateTeam2AoA(team, leader, 5, serv.NewRand())
+	split := []common.Vote{
+		common.CreateVote(1, agentIDs[0], agentIDs[1]),
+		common.CreateVote(1, agentIDs[1], agentIDs[2]),
+		common.CreateVote(0, agentIDs[2], uuid.Nil),
+	}
+	assert.Equal(t, uuid.Nil, aoa.GetVoteResult(split))
+}
@@ -0,0 +1,104 @@
+package main
+
+/*
+* Code to test how the AoAs decide which orphans join their team
+ */
+
+import (
+	"math/rand"
+	"testing"
+
+	agents "github.com/ADimoska/SOMASExtended/agents"
+	common "github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMajorityAdmission(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	votes := map[uuid.UUID]bool{a: true, b: true, c: false}
+	assert.True(t, common.MajorityAdmission(votes, 0.6))
+	assert.False(t, common.MajorityAdmission(votes, 0.7))
+	assert.False(t, common.MajorityAdmission(map[uuid.UUID]bool{}, 0.7))
+}
+
+func TestLeaderVetoAdmission(t *testing.T) {
+	leader, a, b := uuid.New(), uuid.New(), uuid.New()
+	team := common.NewTeam(uuid.New())
+	team.Agents = []uuid.UUID{leader, a, b}
+	aoa := common.CreateTeam2AoA(team, leader, 5, rand.New(rand.NewSource(1))).(common.IAdmissionAoA)
+
+	orphan := uuid.New()
+	assert.True(t, aoa.DecideAdmission(orphan, 10, map[uuid.UUID]bool{leader: true, a: true, b: false}, 0.6).Admitted)
+	// the majority is not enough without the leader
+	assert.False(t, aoa.DecideAdmission(orphan, 10, map[uuid.UUID]bool{leader: false, a: true, b: true}, 0.6).Admitted)
+}
+
+func TestRankWeightedAdmission(t *testing.T) {
+	senior, a, b := uuid.New(), uuid.New(), uuid.New()
+	team := common.NewTeam(uuid.New())
+	team.Agents = []uuid.UUID{senior, a, b}
+	aoa := common.CreateTeam4AoA(team)
+	for i := 0; i < 3; i++ {
+		aoa.RankUp(senior)
+	}
+
+	orphan := uuid.New()
+	// rank C (weight 3) outweighs two rank F members (weight 1 each)
+	assert.True(t, aoa.DecideAdmission(orphan, 10, map[uuid.UUID]bool{senior: true, a: false, b: false}, 0.6).Admitted)
+	// the orphan is only ranked once it has joined
+	_, ranked := aoa.GetRanks()[orphan]
+	assert.False(t, ranked)
+	aoa.OnMemberAdmitted(orphan)
+	assert.Equal(t, "F", aoa.GetRanks()[orphan])
+	assert.False(t, aoa.DecideAdmission(uuid.New(), 10, map[uuid.UUID]bool{senior: false, a: true, b: true}, 0.6).Admitted)
+}
+
+// Members the AoA has not ranked yet vote as rank F
+func TestUnrankedAdmission(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	// the members joined after the AoA was created
+	team := common.NewTeam(uuid.New())
+	aoa := common.CreateTeam4AoA(team)
+	team.Agents = []uuid.UUID{a, b, c}
+	votes := map[uuid.UUID]bool{a: true, b: true, c: false}
+	assert.Empty(t, aoa.GetRanks())
+	assert.True(t, aoa.DecideAdmission(uuid.New(), 10, votes, 0.6).Admitted)
+}
+
+/*
+* Test that an orphan admitted by a team with an entry fee pays it into the
+* team's common pool
+ */
+func TestAdmissionEntryFee(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+
+	teamID := serv.CreateAndInitTeamWithAgents(agentIDs)
+	team := serv.GetTeamFromTeamID(teamID)
+	team.TeamAoA = common.CreateTeam3AoA(team)
+	team.TeamAoAID = 3
+
+	orphan := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	serv.AddAgent(orphan)
+	orphan.SetAoARanking([]int{3})
+	orphan.SetTrueScore(30)
+
+	serv.PickUpOrphans()
+	serv.AllocateOrphans()
+
+	assert.Equal(t, teamID, orphan.GetTeamID())
+	// the tax on a score of 30: 20% of 15 plus 40% of 10
+	assert.Equal(t, 23, orphan.GetTrueScore())
+	assert.Equal(t, 7, team.GetCommonPool())
+
+	allocated := 0
+	for _, event := range serv.DataRecorder.Events {
+		if event.Type == gameRecorder.OrphanAllocatedEvent {
+			allocated++
+			assert.Equal(t, 7, event.Orphan.EntryFee)
+		}
+	}
+	assert.Equal(t, 1, allocated)
+}
@@ -0,0 +1,84 @@
+package main
+
+/*
+* Code to test that agent records hold the contributions and withdrawals the server applied
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	agents "github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentRecordLies(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            12,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             5,
+			ForcedAoA:        1,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team1", Count: 6, AgentType: "Honest"},
+			{Agent: "Team1", Count: 3, AgentType: "CheatShortTerm"},
+			{Agent: "Team1", Count: 3, AgentType: "CheatLongTerm"},
+			{Agent: "Base", Count: 3},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+
+	dishonesty := map[uuid.UUID]int{}
+	lastRecorded := map[uuid.UUID][2]int{}
+	for _, turn := range serv.DataRecorder.TurnRecords {
+		agentsContributed, agentsWithdrew := 0, 0
+		for _, agent := range turn.AgentRecords {
+			assert.Equal(t, agent.StatedContribution-agent.Contribution, agent.ContributionLie)
+			assert.Equal(t, agent.Withdrawal-agent.StatedWithdrawal, agent.WithdrawalLie)
+			// the first turn of an iteration is not recorded, so its lies only show in the total
+			if last, ok := lastRecorded[agent.AgentID]; ok && last == [2]int{agent.IterationNumber, agent.TurnNumber - 1} {
+				assert.Equal(t, dishonesty[agent.AgentID]+max(0, agent.ContributionLie)+max(0, agent.WithdrawalLie), agent.CumulativeDishonesty)
+			}
+			lastRecorded[agent.AgentID] = [2]int{agent.IterationNumber, agent.TurnNumber}
+			dishonesty[agent.AgentID] = agent.CumulativeDishonesty
+			agentsContributed += agent.Contribution
+			agentsWithdrew += agent.Withdrawal
+		}
+
+		// the agent records add up to what went through the pools
+		teamsContributed, teamsWithdrew := 0, 0
+		for _, team := range turn.TeamRecords {
+			teamsContributed += team.TotalContributed
+			teamsWithdrew += team.TotalWithdrawn
+		}
+		assert.Equal(t, teamsContributed, agentsContributed)
+		assert.Equal(t, teamsWithdrew, agentsWithdrew)
+	}
+
+	// the cheaters get caught out by the ground truth, and honest agents never do
+	liars := 0
+	for agentID, total := range dishonesty {
+		if team1Agent, ok := serv.GetAgentMap()[agentID].(*agents.Team1Agent); ok && team1Agent.GetAgentType() != int(agents.Honest) {
+			if total > 0 {
+				liars++
+			}
+			continue
+		}
+		assert.Equal(t, 0, total, "honest agent %v", agentID)
+	}
+	assert.Greater(t, liars, 0)
+}
@@ -0,0 +1,160 @@
+package main
+
+/*
+* Code to test what agents can learn from their view of the server
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/gameRecorder"
+	baseServer "github.com/MattSScott/basePlatformSOMAS/v2/pkg/server"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// The information requests recorded for an agent, in order
+func requestsBy(events []gameRecorder.EventRecord, agentID uuid.UUID) []gameRecorder.InformationRequested {
+	requests := []gameRecorder.InformationRequested{}
+	for _, event := range events {
+		if event.Type == gameRecorder.InformationRequestedEvent && event.AgentID == agentID {
+			requests = append(requests, *event.Request)
+		}
+	}
+	return requests
+}
+
+func TestAgentViewHidesOtherTeams(t *testing.T) {
+	serv, _ := CreateTestServer()
+	serv.Init(3)
+
+	member := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	outsider := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	serv.AddAgent(member)
+	serv.AddAgent(outsider)
+	teamID := serv.CreateAndInitTeamWithAgents([]uuid.UUID{member.GetID()})
+	team := serv.GetTeamFromTeamID(teamID)
+	common.AdoptAoA(1, team, serv)
+	team.SetCommonPool(25)
+
+	// a member sees its own team's pool and AoA
+	assert.Equal(t, 25, member.Server.GetTeamCommonPool())
+	view := member.Server.GetTeamAoA()
+	assert.Equal(t, 1, view.GetAoAID())
+	assert.Equal(t, team.TeamAoA.GetExpectedContribution(member.GetID(), 30), view.GetExpectedContribution(member.GetID(), 30))
+	// but can only read it
+	_, mutable := view.(common.IArticlesOfAssociation)
+	assert.False(t, mutable)
+	_, ranked := view.(common.IAgentRankView)
+	assert.True(t, ranked)
+	// an agent without a team sees neither, and can not inspect other agents
+	assert.Equal(t, 0, outsider.Server.GetTeamCommonPool())
+	assert.Nil(t, outsider.Server.GetTeamAoA())
+	assert.Nil(t, outsider.Server.AccessAgentByID(member.GetID()))
+	// membership is public
+	assert.Equal(t, teamID, outsider.Server.GetAgentTeamID(member.GetID()))
+	assert.Equal(t, []uuid.UUID{member.GetID()}, outsider.Server.GetAgentsInTeam(teamID))
+
+	// an agent can only add itself to a team
+	outsider.Server.AddAgentToTeam(member.GetID(), uuid.Nil)
+	outsider.Server.AddAgentToTeam(uuid.New(), teamID)
+	assert.Equal(t, []uuid.UUID{member.GetID()}, team.Agents)
+
+	assert.Equal(t, []gameRecorder.InformationRequested{
+		{Query: "GetTeamCommonPool", SubjectID: teamID, Allowed: true},
+		{Query: "GetTeamAoA", SubjectID: teamID, Allowed: true},
+	}, requestsBy(serv.DataRecorder.Events, member.GetID()))
+	assert.Equal(t, []gameRecorder.InformationRequested{
+		{Query: "GetTeamCommonPool", SubjectID: uuid.Nil, Allowed: false},
+		{Query: "GetTeamAoA", SubjectID: uuid.Nil, Allowed: false},
+		{Query: "AccessAgentByID", SubjectID: member.GetID(), Allowed: false},
+		{Query: "GetAgentTeamID", SubjectID: member.GetID(), Allowed: true},
+		{Query: "GetAgentsInTeam", SubjectID: teamID, Allowed: true},
+	}, requestsBy(serv.DataRecorder.Events, outsider.GetID()))
+}
+
+// Test that the agents of a full game only learn what they are allowed to
+func TestAgentViewInGame(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            6,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             6,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+			{Agent: "Base", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+
+	requests := 0
+	for _, event := range serv.DataRecorder.Events {
+		if event.Type != gameRecorder.InformationRequestedEvent {
+			continue
+		}
+		requests++
+		assert.NotEqual(t, uuid.Nil, event.AgentID)
+		assert.True(t, event.Request.Allowed, event.Request.Query)
+	}
+	assert.Greater(t, requests, 0)
+}
+
+// Test that agents can be built on a view directly, and not on a server that has none
+func TestAgentBuiltWithoutServer(t *testing.T) {
+	serv, _ := CreateTestServer()
+	serv.Init(3)
+	agent := agents.GetBaseAgents(serv.NewAgentView(), agents.AgentConfig{})
+	assert.NotEqual(t, uuid.Nil, agent.GetID())
+
+	plain := baseServer.CreateBaseServer[common.IExtendedAgent](1, 1, time.Millisecond, 1)
+	assert.Panics(t, func() { agents.GetBaseAgents(plain, agents.AgentConfig{}) })
+}
+
+// Test that agents may ask for the score of their teammates that died, and of no one else
+func TestAgentViewKilledScore(t *testing.T) {
+	serv, _ := CreateTestServer()
+	serv.Init(3)
+
+	member := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	teammate := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	outsider := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	for _, agent := range []*agents.ExtendedAgent{member, teammate, outsider} {
+		serv.AddAgent(agent)
+		agent.SetTrueScore(1000)
+	}
+	serv.CreateAndInitTeamWithAgents([]uuid.UUID{member.GetID(), teammate.GetID()})
+	serv.CreateAndInitTeamWithAgents([]uuid.UUID{outsider.GetID()})
+
+	// below any threshold
+	teammate.SetTrueScore(-1)
+	serv.ApplyThreshold()
+	assert.True(t, serv.IsAgentDead(teammate.GetID()))
+
+	member.Server.GetAgentKilledScore(teammate.GetID())
+	member.Server.GetAgentKilledScore(outsider.GetID())
+	outsider.Server.GetAgentKilledScore(teammate.GetID())
+	assert.Equal(t, []gameRecorder.InformationRequested{
+		{Query: "GetAgentKilledScore", SubjectID: teammate.GetID(), Allowed: true},
+		{Query: "GetAgentKilledScore", SubjectID: outsider.GetID(), Allowed: false},
+	}, requestsBy(serv.DataRecorder.Events, member.GetID()))
+	assert.Equal(t, []gameRecorder.InformationRequested{
+		{Query: "GetAgentKilledScore", SubjectID: teammate.GetID(), Allowed: false},
+	}, requestsBy(serv.DataRecorder.Events, outsider.GetID()))
+}
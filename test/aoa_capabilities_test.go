@@ -0,0 +1,53 @@
+package main
+
+/*
+* Code to test that the AoAs expose the optional capabilities the server looks for
+ */
+
+import (
+	"testing"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAoACapabilities(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(agentIDs))
+
+	_, ok := common.CreateTeam1AoA(team, serv.NewRand()).(common.IPreIterationAoA)
+	assert.True(t, ok, "Team1 AoA should run pre-iteration logic")
+
+	_, ok = common.CreateTeam2AoA(team, agentIDs[0], 5, serv.NewRand()).(common.ILeaderElectionAoA)
+	assert.True(t, ok, "Team2 AoA should have a leader")
+
+	var team4AoA common.IArticlesOfAssociation = common.CreateTeam4AoA(team)
+	_, ok = team4AoA.(common.IRankedWithdrawalAoA)
+	assert.True(t, ok, "Team4 AoA should rank withdrawals")
+	_, ok = team4AoA.(common.IPunishmentVoteAoA)
+	assert.True(t, ok, "Team4 AoA should vote on punishments")
+
+	_, ok = common.CreateTeam5AoA(serv.NewRand()).(common.IResourceAllocationAoA)
+	assert.True(t, ok, "Team5 AoA should allocate resources")
+
+	// AoAs only carry the capabilities they use
+	fixedAoA := common.CreateFixedAoA(1, serv.NewRand())
+	_, ok = fixedAoA.(common.IRankedWithdrawalAoA)
+	assert.False(t, ok)
+	_, ok = fixedAoA.(common.IResourceAllocationAoA)
+	assert.False(t, ok)
+	_, ok = fixedAoA.(common.ILeaderElectionAoA)
+	assert.False(t, ok)
+
+	// the base agents take part in every AoA
+	for _, agent := range serv.GetAgentMap() {
+		_, ok = agent.(common.IRankBoundaryAgent)
+		assert.True(t, ok)
+		_, ok = agent.(common.ILeaderElectionAgent)
+		assert.True(t, ok)
+		_, ok = agent.(common.IRankedWithdrawalAgent)
+		assert.True(t, ok)
+		_, ok = agent.(common.IPunishmentVoteAgent)
+		assert.True(t, ok)
+	}
+}
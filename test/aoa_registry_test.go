@@ -0,0 +1,57 @@
+package main
+
+/*
+* Code to test that AoAs can be added through the registry
+ */
+
+import (
+	"testing"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinAoAsRegistered(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, common.RegisteredAoAIDs())
+
+	// ids must be unique and positive
+	assert.Error(t, common.RegisterAoA(common.AoARegistration{ID: 3, Name: "Duplicate", Constructor: func(team *common.Team, server common.ITurnServer) common.IArticlesOfAssociation {
+		return common.CreateTeam3AoA(team)
+	}}))
+	assert.Error(t, common.RegisterAoA(common.AoARegistration{ID: 0, Name: "Zero"}))
+}
+
+func TestRegisteredAoAIsAdopted(t *testing.T) {
+	const variantID = 42
+	hookRuns := 0
+	err := common.RegisterAoA(common.AoARegistration{
+		ID:   variantID,
+		Name: "Team3Variant",
+		Constructor: func(team *common.Team, server common.ITurnServer) common.IArticlesOfAssociation {
+			return common.CreateTeam3AoA(team)
+		},
+		PostCreate: []common.AoAHook{
+			func(team *common.Team, server common.ITurnServer) {
+				hookRuns++
+			},
+		},
+	})
+	assert.NoError(t, err)
+	defer common.UnregisterAoA(variantID)
+
+	// agents created after the registration rank the new AoA too
+	serv, agentIDs := CreateTestServer()
+	for _, agent := range serv.GetAgentMap() {
+		assert.Contains(t, agent.GetAoARanking(), variantID)
+	}
+
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(agentIDs))
+	assert.Equal(t, variantID, common.AdoptAoA(variantID, team, serv))
+	assert.Equal(t, variantID, team.TeamAoAID)
+	assert.IsType(t, &common.Team3AoA{}, team.TeamAoA)
+	assert.Equal(t, 1, hookRuns)
+
+	// unknown ids fall back to the fixed AoA
+	assert.Equal(t, 0, common.AdoptAoA(variantID+1, team, serv))
+	assert.Equal(t, 0, team.TeamAoAID)
+}
@@ -0,0 +1,119 @@
+package main
+
+/*
+* Code to test the agent that learns which AoA to vote for
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAoAOutcomeReward(t *testing.T) {
+	assert.Equal(t, 0.0, agents.AoAOutcome{}.Reward())
+	assert.Equal(t, 0.5, agents.AoAOutcome{Survived: true}.Reward())
+	assert.Equal(t, 0.75, agents.AoAOutcome{Survived: true, Score: 50}.Reward())
+	assert.InDelta(t, 0.55, agents.AoAOutcome{Survived: true, Score: 50, Punished: 2}.Reward(), 1e-9)
+	// never below 0
+	assert.Equal(t, 0.0, agents.AoAOutcome{Punished: 3}.Reward())
+}
+
+func TestBanditRanksUntriedAoAsFirst(t *testing.T) {
+	serv, _ := CreateTestServer()
+	serv.Init(3)
+	bandit := agents.CreateAoABanditAgent(serv, agents.AgentConfig{})
+	serv.AddAgent(bandit)
+	assert.ElementsMatch(t, common.RegisteredAoAIDs(), bandit.GetAoARanking())
+
+	teamID := serv.CreateAndInitTeamWithAgents([]uuid.UUID{bandit.GetID()})
+	team := serv.GetTeamFromTeamID(teamID)
+	aoaIDs := common.RegisteredAoAIDs()
+	// live through one iteration under every AoA but the last
+	for i, aoaID := range aoaIDs[:len(aoaIDs)-1] {
+		common.AdoptAoA(aoaID, team, serv)
+		bandit.OnTurnStart(i, 1)
+		bandit.SetTrueScore(10 * i)
+		bandit.OnIterationEnd(i)
+	}
+	bandit.ResetForIteration(common.FullMemory())
+
+	assert.Len(t, bandit.Outcomes, len(aoaIDs)-1)
+	assert.Equal(t, aoaIDs[0], bandit.Outcomes[0].AoAID)
+	assert.True(t, bandit.Outcomes[0].Survived)
+	// the AoA it has never lived under comes first, then the best one so far
+	ranking := bandit.GetAoARanking()
+	assert.ElementsMatch(t, aoaIDs, ranking)
+	assert.Equal(t, aoaIDs[len(aoaIDs)-1], ranking[0])
+	assert.Equal(t, aoaIDs[len(aoaIDs)-2], ranking[1])
+
+	// without memory everything is untried again
+	bandit.ResetForIteration(common.MemoryPolicy{Retention: common.MemoryNone})
+	assert.Empty(t, bandit.MeanRewards())
+	assert.Empty(t, bandit.Outcomes)
+}
+
+// Test that an agent that died is credited with no score
+func TestBanditDeadOutcome(t *testing.T) {
+	serv, _ := CreateTestServer()
+	serv.Init(3)
+	bandit := agents.CreateAoABanditAgent(serv, agents.AgentConfig{})
+	serv.AddAgent(bandit)
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents([]uuid.UUID{bandit.GetID()}))
+	common.AdoptAoA(common.RegisteredAoAIDs()[0], team, serv)
+
+	bandit.OnTurnStart(0, 1)
+	// below any threshold
+	bandit.SetTrueScore(-1)
+	serv.ApplyThreshold()
+	assert.True(t, serv.IsAgentDead(bandit.GetID()))
+	bandit.OnIterationEnd(0)
+	assert.Equal(t, agents.AoAOutcome{AoAID: common.RegisteredAoAIDs()[0]}, bandit.Outcomes[0])
+}
+
+func TestBanditAgentsLearnInGame(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       4,
+			Turns:            6,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             5,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Bandit", Count: 8, Exploration: 0.5},
+			{Agent: "Team4", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+
+	learnt := 0
+	for _, agent := range serv.GetAgentMap() {
+		bandit, ok := agent.(*agents.AoABanditAgent)
+		if !ok {
+			continue
+		}
+		assert.Equal(t, 0.5, bandit.Exploration)
+		assert.ElementsMatch(t, common.RegisteredAoAIDs(), bandit.GetAoARanking())
+		assert.LessOrEqual(t, len(bandit.Outcomes), 4)
+		for _, outcome := range bandit.Outcomes {
+			assert.Contains(t, common.RegisteredAoAIDs(), outcome.AoAID)
+		}
+		learnt += len(bandit.Outcomes)
+	}
+	assert.Greater(t, learnt, 0)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+)
+
+// Test that a YAML scenario is parsed and turned into a server with the described population
+func TestParseAndBuildConfig(t *testing.T) {
+	data := []byte(`
+server:
+  iterations: 1
+  turns: 5
+  maxDuration: 10ms
+  messageBandwidth: 10
+  thresholdTurns: 2
+population:
+  - agent: Team1
+    count: 2
+    agentType: CheatLongTerm
+    initScore: 7
+  - agent: Base
+    count: 3
+    aoaRanking: [3, 1]
+`)
+	cfg, err := config.ParseConfig(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.MaxDuration != 10*time.Millisecond {
+		t.Errorf("expected max duration of 10ms, got %v", cfg.Server.MaxDuration)
+	}
+
+	serv, err := config.BuildSimulation(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	agentMap := serv.GetAgentMap()
+	if len(agentMap) != 5 {
+		t.Fatalf("expected 5 agents, got %d", len(agentMap))
+	}
+
+	rankedAgents := 0
+	for _, agent := range agentMap {
+		ranking := agent.GetAoARanking()
+		if len(ranking) == 2 && ranking[0] == 3 && ranking[1] == 1 {
+			rankedAgents++
+		} else if agent.GetTrueScore() != 7 {
+			t.Errorf("expected Team1 agent to start with score 7, got %d", agent.GetTrueScore())
+		}
+	}
+	if rankedAgents != 3 {
+		t.Errorf("expected 3 agents with the configured AoA ranking, got %d", rankedAgents)
+	}
+}
+
+// Test that invalid scenarios are rejected before a server is built
+func TestInvalidConfig(t *testing.T) {
+	invalid := map[string]string{
+		"unknown agent":  "server: {iterations: 1, turns: 1, maxDuration: 1ms, messageBandwidth: 1, thresholdTurns: 1}\npopulation: [{agent: Team99, count: 1}]",
+		"untyped agent":  "server: {iterations: 1, turns: 1, maxDuration: 1ms, messageBandwidth: 1, thresholdTurns: 1}\npopulation: [{agent: Team4, count: 1, agentType: Honest}]",
+		"unknown type":   "server: {iterations: 1, turns: 1, maxDuration: 1ms, messageBandwidth: 1, thresholdTurns: 1}\npopulation: [{agent: Team1, count: 1, agentType: Liar}]",
+		"no turns":       "server: {iterations: 1, turns: 0, maxDuration: 1ms, messageBandwidth: 1, thresholdTurns: 1}\npopulation: [{agent: Base, count: 1}]",
+		"no population":  "server: {iterations: 1, turns: 1, maxDuration: 1ms, messageBandwidth: 1, thresholdTurns: 1}",
+		"zero threshold": "server: {iterations: 1, turns: 1, maxDuration: 1ms, messageBandwidth: 1, thresholdTurns: 0}\npopulation: [{agent: Base, count: 1}]",
+	}
+	for name, data := range invalid {
+		if _, err := config.ParseConfig([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// Test that the default scenario file creates the same population, in the same order, as DefaultConfig
+func TestDefaultScenarioPopulation(t *testing.T) {
+	cfg, err := config.LoadConfig("../scenarios/default.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(config.DefaultConfig().Population, cfg.Population) {
+		t.Errorf("expected the population of DefaultConfig, got %+v", cfg.Population)
+	}
+}
+
+// Test that a server built from an invalid game or threshold policy falls back to the defaults
+func TestNewServerDefaults(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.Game = config.GameConfig{Type: "dice", Dice: -1}
+	cfg.Server.Threshold = config.ThresholdConfig{Policy: "linear", Spread: -1}
+
+	serv := config.NewServer(cfg)
+	if !reflect.DeepEqual(common.DefaultResourceGame(), serv.GetResourceGame()) {
+		t.Errorf("expected the default game, got %+v", serv.GetResourceGame())
+	}
+	if !reflect.DeepEqual(common.DefaultThresholdPolicy(), serv.GetThresholdPolicy()) {
+		t.Errorf("expected the default threshold policy, got %+v", serv.GetThresholdPolicy())
+	}
+}
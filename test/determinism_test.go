@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func runSeededSimulation(t *testing.T, seed int64) []byte {
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            15,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             seed,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team2", Count: 4},
+			{Agent: "Team1", Count: 3, AgentType: "Honest"},
+			{Agent: "Team1", Count: 1, AgentType: "CheatShortTerm"},
+			{Agent: "Base", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serv.Start()
+
+	records, err := json.Marshal(serv.DataRecorder.TurnRecords)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return records
+}
+
+// Test that two runs with the same seed produce identical turn records
+func TestSameSeedSameRecords(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	first := runSeededSimulation(t, 42)
+	second := runSeededSimulation(t, 42)
+	if string(first) != string(second) {
+		t.Errorf("expected identical records for the same seed")
+	}
+
+	other := runSeededSimulation(t, 43)
+	if string(first) == string(other) {
+		t.Errorf("expected different records for a different seed")
+	}
+}
+
+// The IDs of the agents of a seeded population, in order
+func seededAgentIDs(t *testing.T) []uuid.UUID {
+	cfg := &config.SimulationConfig{
+		Server:     config.ServerConfig{Iterations: 1, Turns: 1, MaxDuration: time.Millisecond, MessageBandwidth: 1, ThresholdTurns: 1, Seed: 7},
+		Population: []config.PopulationEntry{{Agent: "Base", Count: 20}},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	return common.SortedKeys(serv.GetAgentMap())
+}
+
+// Test that agent IDs do not depend on UUIDs drawn elsewhere while the agents are created
+func TestAgentIDsIgnoreOtherUUIDs(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	first := seededAgentIDs(t)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				uuid.New()
+			}
+		}
+	}()
+	second := seededAgentIDs(t)
+	close(done)
+	assert.Equal(t, first, second)
+}
@@ -0,0 +1,127 @@
+package main
+
+/*
+* Code to test the lifecycle notifications the server sends to agents
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// An agent that keeps every lifecycle notification
+type lifecycleListener struct {
+	*agents.ExtendedAgent
+	turnsStarted     [][2]int
+	turnResults      []common.TurnResults
+	thresholdResults []common.ThresholdResult
+	teammateDeaths   map[uuid.UUID]int
+	revivals         int
+	iterationsEnded  []int
+}
+
+func (ll *lifecycleListener) OnTurnStart(iteration int, turn int) {
+	ll.turnsStarted = append(ll.turnsStarted, [2]int{iteration, turn})
+}
+
+func (ll *lifecycleListener) OnTurnEnd(results common.TurnResults) {
+	ll.turnResults = append(ll.turnResults, results)
+}
+
+func (ll *lifecycleListener) OnThresholdApplied(result common.ThresholdResult) {
+	ll.thresholdResults = append(ll.thresholdResults, result)
+}
+
+func (ll *lifecycleListener) OnTeammateDied(agentID uuid.UUID, score int) {
+	ll.teammateDeaths[agentID] = score
+}
+
+func (ll *lifecycleListener) OnRevived() {
+	ll.revivals++
+}
+
+func (ll *lifecycleListener) OnIterationEnd(iteration int) {
+	ll.iterationsEnded = append(ll.iterationsEnded, iteration)
+}
+
+// Run a short game with listeners added to the population and a fixed threshold
+func runLifecycleScenario(t *testing.T, threshold int) []*lifecycleListener {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            5,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             9,
+			Threshold:        config.ThresholdConfig{Policy: "fixed", Value: threshold},
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	listeners := []*lifecycleListener{}
+	for i := 0; i < 4; i++ {
+		listener := &lifecycleListener{
+			ExtendedAgent:  agents.GetBaseAgents(serv, agents.AgentConfig{}),
+			teammateDeaths: make(map[uuid.UUID]int),
+		}
+		serv.AddAgent(listener)
+		listeners = append(listeners, listener)
+	}
+	serv.Start()
+	return listeners
+}
+
+func TestLifecycleWhenAllSurvive(t *testing.T) {
+	for _, listener := range runLifecycleScenario(t, 0) {
+		assert.Equal(t, [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 0}, {1, 1}, {1, 2}, {1, 3}, {1, 4}}, listener.turnsStarted)
+		assert.Len(t, listener.turnResults, 10)
+		for _, results := range listener.turnResults {
+			assert.Empty(t, results.Died)
+			assert.Equal(t, 12, results.LivingAgents)
+			assert.Equal(t, results.Turn == 3, results.ThresholdApplied)
+		}
+		// the threshold is applied in turn 3 of each iteration
+		assert.Equal(t, []common.ThresholdResult{{Turn: 3, Score: listener.thresholdResults[0].Score}, {Turn: 3, Score: listener.thresholdResults[1].Score}}, listener.thresholdResults)
+		assert.Empty(t, listener.teammateDeaths)
+		assert.Zero(t, listener.revivals)
+		assert.Equal(t, []int{0, 1}, listener.iterationsEnded)
+	}
+}
+
+func TestLifecycleWhenAllDie(t *testing.T) {
+	listeners := runLifecycleScenario(t, 10000)
+	teammateDeaths := 0
+	for _, listener := range listeners {
+		// every agent dies in turn 3, so the listener is not told about the end of it
+		assert.Equal(t, [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {1, 0}, {1, 1}, {1, 2}, {1, 3}}, listener.turnsStarted)
+		assert.Len(t, listener.turnResults, 6)
+		assert.Empty(t, listener.thresholdResults)
+		// revived at the start of the second iteration, and told about the end of both
+		assert.Equal(t, 1, listener.revivals)
+		assert.Equal(t, []int{0, 1}, listener.iterationsEnded)
+		for teammateID, score := range listener.teammateDeaths {
+			assert.NotEqual(t, listener.GetID(), teammateID)
+			assert.Less(t, score, 10000)
+		}
+		teammateDeaths += len(listener.teammateDeaths)
+	}
+	// teammates that die before the listener are reported to it
+	assert.Greater(t, teammateDeaths, 0)
+}
@@ -0,0 +1,89 @@
+package main
+
+/*
+* Code to test what agents remember from one iteration to the next
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPolicies(t *testing.T) {
+	full := common.FullMemory()
+	assert.Equal(t, 90, full.RetainInt(90, 70))
+	assert.Equal(t, 12, full.RecentCount(12))
+
+	none, err := common.NewMemoryPolicy(common.MemoryNone, 0)
+	assert.NoError(t, err)
+	assert.False(t, none.Keeps())
+	assert.Equal(t, 70, none.RetainInt(90, 70))
+	assert.Equal(t, 0, none.RecentCount(12))
+
+	decayed, err := common.NewMemoryPolicy(common.MemoryDecayed, 0.5)
+	assert.NoError(t, err)
+	assert.True(t, decayed.Keeps())
+	// moves halfway back to the initial value
+	assert.Equal(t, 80, decayed.RetainInt(90, 70))
+	assert.Equal(t, 60, decayed.RetainInt(50, 70))
+	assert.Equal(t, 6, decayed.RecentCount(12))
+
+	_, err = common.NewMemoryPolicy(common.MemoryDecayed, 1.5)
+	assert.Error(t, err)
+	_, err = config.MemoryConfig{Retention: "some"}.NewMemoryPolicy()
+	assert.Error(t, err)
+}
+
+// An agent that keeps the memory policies it is reset with
+type memoryListener struct {
+	*agents.ExtendedAgent
+	resets []common.MemoryPolicy
+}
+
+func (ml *memoryListener) ResetForIteration(policy common.MemoryPolicy) {
+	ml.ExtendedAgent.ResetForIteration(policy)
+	ml.resets = append(ml.resets, policy)
+}
+
+func TestMemoryPolicyAppliedEveryIteration(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       3,
+			Turns:            4,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             4,
+			Memory:           config.MemoryConfig{Retention: "decayed", Decay: 0.25},
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	listener := &memoryListener{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{})}
+	serv.AddAgent(listener)
+	serv.Start()
+
+	// dead or alive, the agent is reset at the start of every iteration
+	policy := common.MemoryPolicy{Retention: common.MemoryDecayed, Decay: 0.25}
+	assert.Equal(t, []common.MemoryPolicy{policy, policy, policy}, listener.resets)
+	assert.NotEmpty(t, serv.DataRecorder.TurnRecords)
+	for _, turn := range serv.DataRecorder.TurnRecords {
+		assert.Equal(t, "decayed", turn.CommonRecord.MemoryRetention)
+		assert.Equal(t, 0.25, turn.CommonRecord.MemoryDecay)
+	}
+}
@@ -8,6 +8,7 @@ package main
 import (
 	agents "github.com/ADimoska/SOMASExtended/agents"
 	common "github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
 	envServer "github.com/ADimoska/SOMASExtended/server"
 	baseServer "github.com/MattSScott/basePlatformSOMAS/v2/pkg/server"
 	"reflect"
@@ -198,3 +199,207 @@ func TestAllocateOrphans(t *testing.T) {
 	// All agents in the game are now in one team
 	assert.Equal(t, len(agentIDs), len(serv.GetTeamFromTeamID(teamID).Agents))
 }
+
+// An orphan that applies to the given teams
+type applicantAgent struct {
+	*agents.ExtendedAgent
+	applications []uuid.UUID
+}
+
+func (aa *applicantAgent) GetTeamApplications() []uuid.UUID {
+	return aa.applications
+}
+
+// An agent that never lets an orphan into its team, and counts how often it is asked
+type gatekeeperAgent struct {
+	*agents.ExtendedAgent
+	asked int
+}
+
+func (ga *gatekeeperAgent) VoteOnAgentEntry(candidateID uuid.UUID) bool {
+	ga.asked++
+	return false
+}
+
+// Create a team of gatekeepers with the given AoA ID
+func addGatekeeperTeam(serv *envServer.EnvironmentServer, aoaID int) (uuid.UUID, []*gatekeeperAgent) {
+	gatekeepers := []*gatekeeperAgent{}
+	gatekeeperIDs := []uuid.UUID{}
+	for i := 0; i < 3; i++ {
+		gatekeeper := &gatekeeperAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{})}
+		serv.AddAgent(gatekeeper)
+		gatekeepers = append(gatekeepers, gatekeeper)
+		gatekeeperIDs = append(gatekeeperIDs, gatekeeper.GetID())
+	}
+	teamID := serv.CreateAndInitTeamWithAgents(gatekeeperIDs)
+	serv.GetTeamFromTeamID(teamID).TeamAoAID = aoaID
+	return teamID, gatekeepers
+}
+
+/*
+* Test that an orphan that applies to teams is allocated to the team it applied to,
+* rather than to a team of its preferred AoA
+ */
+func TestOrphanApplications(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	agent_map := serv.GetAgentMap()
+
+	orphan := agentIDs[0]
+	team1ID := serv.CreateAndInitTeamWithAgents(agentIDs[1:10])
+	team2ID := serv.CreateAndInitTeamWithAgents(agentIDs[10:])
+	serv.GetTeamFromTeamID(team1ID).TeamAoAID = 1
+	serv.GetTeamFromTeamID(team2ID).TeamAoAID = 2
+
+	applicant := &applicantAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{}), applications: []uuid.UUID{team2ID}}
+	serv.AddAgent(applicant)
+	applicant.SetAoARanking([]int{1})
+	agent_map[orphan].SetAoARanking([]int{1})
+
+	serv.PickUpOrphans()
+	entry, ok := serv.GetOrphanEntry(applicant.GetID())
+	assert.True(t, ok)
+	assert.Equal(t, []uuid.UUID{team2ID}, entry.Applications)
+	serv.AllocateOrphans()
+
+	assert.Equal(t, team2ID, applicant.GetTeamID())
+	assert.Equal(t, team1ID, agent_map[orphan].GetTeamID())
+	_, ok = serv.GetOrphanEntry(applicant.GetID())
+	assert.False(t, ok)
+}
+
+/*
+* Test that orphans wait in the pool and are not proposed twice to a team that
+* rejected them
+ */
+func TestOrphanRejectionsRemembered(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+
+	orphan := agentIDs[0]
+	serv.CreateAndInitTeamWithAgents(agentIDs[1:])
+	gateTeamID, gatekeepers := addGatekeeperTeam(serv, 1)
+	serv.GetAgentMap()[orphan].SetAoARanking([]int{1})
+
+	for turn := 0; turn < 3; turn++ {
+		serv.PickUpOrphans()
+		serv.AllocateOrphans()
+	}
+
+	entry, ok := serv.GetOrphanEntry(orphan)
+	assert.True(t, ok)
+	assert.Equal(t, 2, entry.Waited)
+	assert.Equal(t, []uuid.UUID{gateTeamID}, entry.RejectedBy)
+	for _, gatekeeper := range gatekeepers {
+		assert.Equal(t, 1, gatekeeper.asked)
+	}
+	// without a cap on rejections, the orphan keeps waiting
+	assert.Equal(t, uuid.Nil, serv.GetAgentMap()[orphan].GetTeamID())
+}
+
+// An agent that neither invites nor accepts anyone into a team
+type lonerAgent struct {
+	*agents.ExtendedAgent
+}
+
+func (la *lonerAgent) DecideTeamForming(agentInfoList []common.ExposedAgentInfo) []uuid.UUID {
+	return []uuid.UUID{}
+}
+
+func (la *lonerAgent) HandleTeamFormationMessage(msg *common.TeamFormationMessage) {}
+
+/*
+* Test that orphans rejected too often, and that will not form a team themselves,
+* are put in a new team together by the server
+ */
+func TestRejectedOrphansFormTeam(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	serv.SetMaxOrphanRejections(1)
+
+	serv.CreateAndInitTeamWithAgents(agentIDs)
+	gateTeamID, _ := addGatekeeperTeam(serv, 1)
+	orphans := []uuid.UUID{}
+	for i := 0; i < 2; i++ {
+		loner := &lonerAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{})}
+		serv.AddAgent(loner)
+		loner.SetAoARanking([]int{1})
+		orphans = append(orphans, loner.GetID())
+	}
+
+	serv.PickUpOrphans()
+	serv.AllocateOrphans()
+	serv.FormOrphanTeams()
+
+	newTeamID := serv.GetAgentMap()[orphans[0]].GetTeamID()
+	assert.NotEqual(t, uuid.Nil, newTeamID)
+	assert.NotEqual(t, gateTeamID, newTeamID)
+	assert.ElementsMatch(t, orphans, serv.GetAgentsInTeam(newTeamID))
+	assert.NotNil(t, serv.GetTeamFromTeamID(newTeamID).TeamAoA)
+
+	rejected, formed := 0, 0
+	for _, event := range serv.DataRecorder.Events {
+		switch event.Type {
+		case gameRecorder.OrphanRejectedEvent:
+			rejected++
+			assert.Equal(t, gateTeamID, event.TeamID)
+			assert.Equal(t, 1, event.Orphan.Rejections)
+		case gameRecorder.OrphanTeamFormedEvent:
+			formed++
+			assert.Equal(t, newTeamID, event.TeamID)
+			assert.False(t, event.Orphan.Negotiated)
+		}
+	}
+	assert.Equal(t, 2, rejected)
+	assert.Equal(t, 2, formed)
+}
+
+/*
+* Test that orphans no team will take form a new team among themselves, which
+* picks its AoA straight away
+ */
+func TestOrphansNegotiateTeam(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+
+	serv.CreateAndInitTeamWithAgents(agentIDs)
+	addGatekeeperTeam(serv, 1)
+	addGatekeeperTeam(serv, 2)
+	orphans := []uuid.UUID{}
+	for i := 0; i < 3; i++ {
+		orphan := agents.GetBaseAgents(serv, agents.AgentConfig{})
+		serv.AddAgent(orphan)
+		orphan.SetAoARanking([]int{1, 2})
+		orphans = append(orphans, orphan.GetID())
+	}
+	teamsBefore := len(serv.GetTeamIDs())
+
+	serv.PickUpOrphans()
+	serv.AllocateOrphans()
+	// every team rejected them, but they can still apply nowhere else
+	for _, orphanID := range orphans {
+		assert.Equal(t, uuid.Nil, serv.GetAgentMap()[orphanID].GetTeamID())
+	}
+	serv.FormOrphanTeams()
+
+	newTeamID := serv.GetAgentMap()[orphans[0]].GetTeamID()
+	assert.NotEqual(t, uuid.Nil, newTeamID)
+	assert.ElementsMatch(t, orphans, serv.GetAgentsInTeam(newTeamID))
+	assert.Equal(t, teamsBefore+1, len(serv.GetTeamIDs()))
+	assert.NotNil(t, serv.GetTeamFromTeamID(newTeamID).TeamAoA)
+	for _, orphanID := range orphans {
+		_, ok := serv.GetOrphanEntry(orphanID)
+		assert.False(t, ok)
+	}
+
+	formed := 0
+	for _, event := range serv.DataRecorder.Events {
+		if event.Type == gameRecorder.OrphanTeamFormedEvent {
+			formed++
+			assert.Equal(t, newTeamID, event.TeamID)
+			assert.True(t, event.Orphan.Negotiated)
+			assert.Equal(t, 2, event.Orphan.Rejections)
+		}
+	}
+	assert.Equal(t, 3, formed)
+}
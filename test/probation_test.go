@@ -0,0 +1,149 @@
+package main
+
+/*
+* Code to test the probation of orphans admitted into a team
+ */
+
+import (
+	"testing"
+
+	agents "github.com/ADimoska/SOMASExtended/agents"
+	common "github.com/ADimoska/SOMASExtended/common"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+	envServer "github.com/ADimoska/SOMASExtended/server"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamProbation(t *testing.T) {
+	team := common.NewTeam(uuid.New())
+	a, b := uuid.New(), uuid.New()
+	team.Agents = []uuid.UUID{a, b}
+	team.StartProbation(a, 2)
+	team.StartProbation(b, 1)
+	assert.True(t, team.IsOnProbation(a))
+	assert.Equal(t, map[uuid.UUID]int{a: 2, b: 1}, team.GetProbationers())
+
+	assert.Equal(t, []uuid.UUID{b}, team.ServeProbation())
+	assert.Equal(t, 1, team.GetProbation(a))
+	assert.False(t, team.IsOnProbation(b))
+	// leaving the team ends the probation
+	team.RemoveAgent(a)
+	assert.Empty(t, team.GetProbationers())
+	assert.Empty(t, team.ServeProbation())
+}
+
+// An agent that withdraws a fixed amount
+type fixedWithdrawalAgent struct {
+	*agents.ExtendedAgent
+	withdrawal int
+}
+
+func (fa *fixedWithdrawalAgent) GetActualWithdrawal(instance common.IExtendedAgent) int {
+	return fa.withdrawal
+}
+
+// Create a team of agents that withdraw nothing, with a greedy orphan admitted into it
+func addProbationTeam(serv *envServer.EnvironmentServer) (*common.Team, *fixedWithdrawalAgent) {
+	memberIDs := []uuid.UUID{}
+	for i := 0; i < 3; i++ {
+		member := &fixedWithdrawalAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{})}
+		serv.AddAgent(member)
+		memberIDs = append(memberIDs, member.GetID())
+	}
+	teamID := serv.CreateAndInitTeamWithAgents(memberIDs)
+	team := serv.GetTeamFromTeamID(teamID)
+	team.TeamAoAID = 1
+
+	orphan := &fixedWithdrawalAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{}), withdrawal: 1000}
+	serv.AddAgent(orphan)
+	orphan.SetAoARanking([]int{1})
+	serv.PickUpOrphans()
+	serv.AllocateOrphans()
+	return team, orphan
+}
+
+/*
+* Test that an admitted orphan is put on probation, sees it, and is recorded
+ */
+func TestOrphanPutOnProbation(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	serv.SetProbation(2, 0.5)
+	serv.CreateAndInitTeamWithAgents(agentIDs)
+
+	team, orphan := addProbationTeam(serv)
+	assert.Equal(t, team.TeamID, orphan.GetTeamID())
+	assert.Equal(t, 2, team.GetProbation(orphan.GetID()))
+	assert.Equal(t, map[uuid.UUID]int{orphan.GetID(): 2}, orphan.Server.GetTeamProbation())
+	assert.Equal(t, map[uuid.UUID]int{orphan.GetID(): 2}, team.RecordTeamStatus().Probation)
+
+	allocated := 0
+	for _, event := range serv.DataRecorder.Events {
+		if event.Type == gameRecorder.OrphanAllocatedEvent {
+			allocated++
+			assert.Equal(t, 2, event.Orphan.Probation)
+		}
+	}
+	assert.Equal(t, 1, allocated)
+}
+
+/*
+* Test that a member on probation withdraws at most its share of an equal split of the
+* pool, and is audited when the team votes for no one
+ */
+func TestProbationRestrictsMember(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	serv.SetProbation(2, 0.5)
+	serv.CreateAndInitTeamWithAgents(agentIDs)
+	team, orphan := addProbationTeam(serv)
+
+	team.SetCommonPool(100)
+	state := &common.TurnState{Team: team}
+	serv.RunDefaultTurnPhase(common.WithdrawPhase, state)
+	// half of an equal split of 100 between 4 members
+	assert.Equal(t, 12, state.Withdrawals[orphan.GetID()].Actual)
+	assert.Equal(t, 88, team.GetCommonPool())
+
+	serv.RunDefaultTurnPhase(common.ContributionAuditPhase, state)
+	assert.Equal(t, orphan.GetID(), state.AuditedAgent)
+
+	// full members withdraw what they like
+	team.StartProbation(orphan.GetID(), 0)
+	state = &common.TurnState{Team: team}
+	serv.RunDefaultTurnPhase(common.WithdrawPhase, state)
+	assert.Equal(t, 88, state.Withdrawals[orphan.GetID()].Actual)
+	serv.RunDefaultTurnPhase(common.ContributionAuditPhase, state)
+	assert.Equal(t, uuid.Nil, state.AuditedAgent)
+}
+
+/*
+* Test that under AoA 5, which charges for audits, a member on probation is audited (and the
+* audit paid for) when the team votes for no one
+ */
+func TestProbationAuditUnderTeam5(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	serv.SetProbation(2, 0.5)
+	serv.CreateAndInitTeamWithAgents(agentIDs)
+	team, orphan := addProbationTeam(serv)
+	team.TeamAoA = common.CreateTeam5AoA(serv.NewRand())
+	team.TeamAoAID = 5
+
+	audit, ok := team.TeamAoA.(common.ITurnPhaseOverrides).OverrideTurnPhase(common.ContributionAuditPhase)
+	assert.True(t, ok)
+	team.SetCommonPool(100)
+	state := &common.TurnState{Team: team, AuditPhase: common.ContributionAuditPhase}
+	audit(serv, state)
+	assert.Equal(t, orphan.GetID(), state.AuditedAgent)
+	assert.Equal(t, 5, state.AuditCost)
+	assert.Equal(t, 95, team.GetCommonPool())
+
+	// nobody is audited, or charged for, once the probation is over
+	team.StartProbation(orphan.GetID(), 0)
+	state = &common.TurnState{Team: team, AuditPhase: common.ContributionAuditPhase}
+	audit(serv, state)
+	assert.Equal(t, uuid.Nil, state.AuditedAgent)
+	assert.Equal(t, 95, team.GetCommonPool())
+}
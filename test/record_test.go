@@ -0,0 +1,161 @@
+package main
+
+/*
+* Code to test the game record: events, CSV export and the JSON Lines stream
+ */
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLRoundTrip(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            10,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             7,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+			{Agent: "Base", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+
+	var record bytes.Buffer
+	serv.DataRecorder.StreamJSONL(&record)
+	serv.Start()
+
+	// every turn and event is on its own line
+	lines := bytes.Count(record.Bytes(), []byte("\n"))
+	assert.Equal(t, len(serv.DataRecorder.TurnRecords)+len(serv.DataRecorder.Events), lines)
+
+	// each team adopts an AoA every iteration, after its members have ranked them
+	adopted, ballots := 0, 0
+	for _, event := range serv.DataRecorder.Events {
+		switch event.Type {
+		case gameRecorder.AoAAdoptedEvent:
+			adopted++
+		case gameRecorder.AoAVoteCastEvent:
+			ballots++
+			assert.NotEmpty(t, event.AoAVote.Ranking)
+		}
+	}
+	assert.Greater(t, adopted, 0)
+	assert.GreaterOrEqual(t, ballots, adopted)
+
+	loaded, err := gameRecorder.ReadJSONL(&record)
+	assert.NoError(t, err)
+
+	expected, _ := json.Marshal(serv.DataRecorder.TurnRecords)
+	actual, _ := json.Marshal(loaded.TurnRecords)
+	assert.JSONEq(t, string(expected), string(actual))
+	assert.Equal(t, serv.DataRecorder.Events, loaded.Events)
+	for agentID := range serv.GetAgentMap() {
+		assert.Equal(t, serv.GetReputation(agentID), loaded.GetReputation(agentID))
+	}
+}
+
+func TestReadJSONLRejectsBadLines(t *testing.T) {
+	_, err := gameRecorder.ReadJSONL(bytes.NewBufferString("{\"turn\":{\"TurnNumber\":1}}\n{}\n"))
+	assert.Error(t, err)
+}
+
+// Recording into a server that was never given a recorder is a no-op
+func TestNilRecorderIgnoresRecords(t *testing.T) {
+	var recorder *gameRecorder.ServerDataRecorder
+	recorder.RecordEvent(gameRecorder.EventRecord{Type: gameRecorder.AgentKickedEvent})
+	recorder.RecordNewTurn(nil, nil, gameRecorder.CommonRecord{}, nil)
+	assert.NoError(t, recorder.Close())
+}
+
+func TestKickAndLeaderEventsRecorded(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	common.SortUUIDs(agentIDs)
+	teamID := serv.CreateAndInitTeamWithAgents(agentIDs)
+	team := serv.GetTeamFromTeamID(teamID)
+	common.AdoptAoA(2, team, serv)
+
+	// kick someone other than the leader
+	kicked := agentIDs[0]
+	if kicked == team.TeamAoA.(common.ILeaderElectionAoA).GetLeader() {
+		kicked = agentIDs[1]
+	}
+	serv.RemoveAgentFromTeam(kicked)
+
+	// agents asking the server for information while voting is recorded too
+	events := []gameRecorder.EventRecord{}
+	eventTypes := []gameRecorder.EventType{}
+	for _, event := range serv.DataRecorder.Events {
+		if event.Type != gameRecorder.InformationRequestedEvent {
+			events = append(events, event)
+			eventTypes = append(eventTypes, event.Type)
+		}
+	}
+	assert.Equal(t, []gameRecorder.EventType{gameRecorder.LeaderElectedEvent, gameRecorder.AgentKickedEvent}, eventTypes)
+
+	leader := events[0]
+	assert.Equal(t, teamID, leader.TeamID)
+	assert.Equal(t, team.TeamAoA.(common.ILeaderElectionAoA).GetLeader(), leader.AgentID)
+	assert.Greater(t, leader.Leader.Votes, 0)
+
+	// the agent's record notes what happened to it this turn
+	serv.RecordTurnInfo()
+	for _, record := range serv.DataRecorder.GetCurrentTurnRecord().AgentRecords {
+		if record.AgentID == kicked {
+			assert.Equal(t, string(gameRecorder.AgentKickedEvent), record.SpecialNote)
+		}
+	}
+	assert.Empty(t, serv.DataRecorder.EventsSinceLastTurn())
+}
+
+func TestEventsExportedToCSV(t *testing.T) {
+	recorder := gameRecorder.CreateRecorder()
+	agentID, teamID := uuid.New(), uuid.New()
+	audit := gameRecorder.NewEventRecord(1, 0, gameRecorder.AuditExecutedEvent, agentID, teamID)
+	audit.Audit = &gameRecorder.AuditExecuted{Phase: "WithdrawalAudit", Caught: true, Cost: 3}
+	recorder.RecordEvent(audit)
+	recorder.RecordEvent(gameRecorder.NewEventRecord(1, 0, gameRecorder.AgentLeftEvent, agentID, teamID))
+
+	outputDir := t.TempDir()
+	assert.NoError(t, gameRecorder.ExportToCSV(recorder, outputDir))
+
+	f, err := os.Open(filepath.Join(outputDir, "event_records.csv"))
+	assert.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	column := map[string]int{}
+	for i, header := range rows[0] {
+		column[header] = i
+	}
+	assert.Equal(t, "true", rows[1][column["Audit.Caught"]])
+	assert.Equal(t, "3", rows[1][column["Audit.Cost"]])
+	assert.Equal(t, "", rows[2][column["Audit.Cost"]])
+	assert.Equal(t, "AgentLeft", rows[2][column["Type"]])
+}
@@ -0,0 +1,97 @@
+package main
+
+/*
+* Code to test the public reputation the server keeps for every agent
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	agents "github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/config"
+	gameRecorder "github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReputationsFromEvents(t *testing.T) {
+	agentID, teamID := uuid.New(), uuid.New()
+	caught := gameRecorder.NewEventRecord(1, 0, gameRecorder.AuditExecutedEvent, agentID, teamID)
+	caught.Audit = &gameRecorder.AuditExecuted{Caught: true}
+	cleared := gameRecorder.NewEventRecord(2, 0, gameRecorder.AuditExecutedEvent, agentID, teamID)
+	cleared.Audit = &gameRecorder.AuditExecuted{Caught: false}
+	events := []gameRecorder.EventRecord{
+		caught,
+		cleared,
+		gameRecorder.NewEventRecord(3, 0, gameRecorder.AgentKickedEvent, agentID, teamID),
+		gameRecorder.NewEventRecord(4, 0, gameRecorder.AgentLeftEvent, agentID, teamID),
+		gameRecorder.NewEventRecord(4, 0, gameRecorder.AgentKilledEvent, agentID, teamID),
+		gameRecorder.NewEventRecord(5, 0, gameRecorder.IterationSurvivedEvent, agentID, uuid.Nil),
+		gameRecorder.NewEventRecord(5, 0, gameRecorder.AoAAdoptedEvent, uuid.Nil, teamID),
+	}
+
+	reputations := gameRecorder.Reputations(events)
+	assert.Len(t, reputations, 1)
+	assert.Equal(t, gameRecorder.Reputation{TeamsLeft: 1, TimesKicked: 1, FailedAudits: 1, IterationsSurvived: 1}, reputations[agentID])
+}
+
+/*
+* Test that the server exposes the reputation of agents to the others
+ */
+func TestReputationExposed(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	serv.Init(3)
+	teamID := serv.CreateAndInitTeamWithAgents(agentIDs)
+
+	kicked := agentIDs[0]
+	serv.DataRecorder.RecordEvent(gameRecorder.NewEventRecord(1, 0, gameRecorder.AgentKickedEvent, kicked, teamID))
+	assert.Equal(t, gameRecorder.Reputation{TimesKicked: 1}, serv.GetReputation(kicked))
+
+	for _, info := range serv.UpdateAndGetAgentExposedInfo() {
+		if info.AgentUUID == kicked {
+			assert.Equal(t, 1, info.Reputation.TimesKicked)
+		} else {
+			assert.Equal(t, gameRecorder.Reputation{}, info.Reputation)
+		}
+	}
+
+	voter := agents.GetBaseAgents(serv, agents.AgentConfig{})
+	serv.AddAgent(voter)
+	assert.Equal(t, 1, voter.Server.GetReputation(kicked).TimesKicked)
+}
+
+func TestReputationInGame(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       3,
+			Turns:            4,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             6,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Base", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+
+	// the server's reputations are those rebuilt from the record
+	reputations := gameRecorder.Reputations(serv.DataRecorder.Events)
+	for agentID := range serv.GetAgentMap() {
+		assert.Equal(t, reputations[agentID], serv.GetReputation(agentID))
+		// living agents survived at least the last iteration
+		assert.GreaterOrEqual(t, reputations[agentID].IterationsSurvived, 1)
+		assert.LessOrEqual(t, reputations[agentID].IterationsSurvived, 3)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+/*
+* Code to test the resource games agents roll in
+ */
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultResourceGame(t *testing.T) {
+	game := common.DefaultResourceGame()
+	assert.Equal(t, 18, game.MaxRoll())
+
+	// every roll beats the one before the first roll
+	assert.False(t, game.IsBust(3, -1))
+	assert.True(t, game.IsBust(10, 10))
+	assert.Equal(t, 0.0, game.BustProbability(-1))
+	// 3d6 sums to at most 10 in 108 of 216 cases
+	assert.InDelta(t, 0.5, game.BustProbability(10), 1e-9)
+	assert.InDelta(t, 1.0, game.BustProbability(18), 1e-9)
+
+	// the expected value of 3d6, nothing can be lost before the first roll
+	assert.InDelta(t, 10.5, game.ExpectedGain(0, -1), 1e-9)
+	// rolling again after an 18 always loses the accumulated score
+	assert.InDelta(t, -30.0, game.ExpectedGain(30, 18), 1e-9)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		roll := game.Roll(rng)
+		assert.GreaterOrEqual(t, roll, 3)
+		assert.LessOrEqual(t, roll, 18)
+	}
+}
+
+func TestDroughtGame(t *testing.T) {
+	game, err := common.NewDroughtGame(2, 4, 0.25)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, game.MaxRoll())
+	// a drought busts anything but the first roll of a turn
+	assert.InDelta(t, 0.25, game.BustProbability(0), 1e-9)
+	assert.InDelta(t, 0.25+0.75/16, game.BustProbability(2), 1e-9)
+	assert.InDelta(t, 0.75*5, game.ExpectedGain(0, -1), 1e-9)
+
+	rng := rand.New(rand.NewSource(1))
+	droughts := 0
+	for i := 0; i < 4000; i++ {
+		if game.Roll(rng) == 0 {
+			droughts++
+		}
+	}
+	assert.InDelta(t, 1000, droughts, 100)
+
+	_, err = common.NewDroughtGame(3, 6, 1.5)
+	assert.Error(t, err)
+	_, err = common.NewDiceGame(0, 6)
+	assert.Error(t, err)
+}
+
+// Test that the scenario's game is used by the server and rejected when invalid
+func TestConfiguredResourceGame(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       1,
+			Turns:            6,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             4,
+			Game:             config.GameConfig{Type: "dice", Dice: 1, Faces: 20},
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, serv.GetResourceGame().MaxRoll())
+	serv.Start()
+
+	rolls := 0
+	for _, turn := range serv.DataRecorder.TurnRecords {
+		for _, roll := range turn.RollRecords {
+			for _, value := range roll.Rolls {
+				rolls++
+				assert.GreaterOrEqual(t, value, 1)
+				assert.LessOrEqual(t, value, 20)
+			}
+		}
+	}
+	assert.Greater(t, rolls, 0)
+
+	cfg.Server.Game = config.GameConfig{Type: "drought", DroughtChance: -0.1}
+	assert.Error(t, cfg.Validate())
+	cfg.Server.Game = config.GameConfig{Type: "poker"}
+	assert.Error(t, cfg.Validate())
+}
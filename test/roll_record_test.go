@@ -0,0 +1,133 @@
+package main
+
+/*
+* Code to test the dice roll traces in the game record
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollRecords(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            12,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             3,
+			ForcedAoA:        2,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 6},
+			{Agent: "Team1", Count: 6},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+
+	rolls := 0
+	for _, turn := range serv.DataRecorder.TurnRecords {
+		summaries := map[uuid.UUID]gameRecorder.AgentRecord{}
+		for _, agent := range turn.AgentRecords {
+			summaries[agent.AgentID] = agent
+		}
+
+		for _, roll := range turn.RollRecords {
+			rolls++
+			assert.Equal(t, turn.CommonRecord.TurnNumber, roll.TurnNumber)
+
+			// every roll but a bust beats the one before
+			for i := 1; i < len(roll.Rolls); i++ {
+				if !roll.Bust || i < len(roll.Rolls)-1 {
+					assert.Greater(t, roll.Rolls[i], roll.Rolls[i-1])
+				}
+			}
+			if roll.Bust {
+				// the first roll can not bust
+				assert.GreaterOrEqual(t, len(roll.Rolls), 2)
+				assert.LessOrEqual(t, roll.Rolls[len(roll.Rolls)-1], roll.Rolls[len(roll.Rolls)-2])
+				assert.Equal(t, 0, roll.TurnScore)
+			} else {
+				sum := 0
+				for _, value := range roll.Rolls {
+					sum += value
+				}
+				assert.Equal(t, sum, roll.TurnScore)
+				// the sequence ends with the decision to stick
+				assert.True(t, roll.Decisions[len(roll.Decisions)-1])
+			}
+
+			summary := summaries[roll.AgentID]
+			assert.Equal(t, len(roll.Rolls), summary.RollRounds)
+			assert.Equal(t, roll.TurnScore, summary.RollTurnScore)
+			assert.Equal(t, roll.Bust, summary.Bust)
+			assert.Equal(t, roll.ControlledBy != uuid.Nil, summary.RollControlled)
+		}
+	}
+	assert.Greater(t, rolls, 0)
+}
+
+// An agent that only decides whether to stick, and keeps the results the server reports
+type stickingAgent struct {
+	*agents.ExtendedAgent
+	stick   bool
+	results []gameRecorder.RollRecord
+}
+
+func (sa *stickingAgent) StickOrAgain(accumulatedScore int, prevRoll int) bool {
+	return sa.stick
+}
+
+func (sa *stickingAgent) HandleRollResult(roll gameRecorder.RollRecord) {
+	sa.results = append(sa.results, roll)
+}
+
+// Test that the server rolls the dice and applies the turn score
+func TestServerRollsDice(t *testing.T) {
+	serv, _ := CreateTestServer()
+	serv.SetSeed(1)
+	sticker := &stickingAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{InitScore: 10}), stick: true}
+	gambler := &stickingAgent{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{InitScore: 10}), stick: false}
+	serv.AddAgent(sticker)
+	serv.AddAgent(gambler)
+
+	for i := 0; i < 20; i++ {
+		serv.RollDice(sticker.GetID())
+		serv.RollDice(gambler.GetID())
+	}
+
+	// sticking after the first roll keeps it
+	total := 10
+	for _, roll := range sticker.results {
+		assert.Len(t, roll.Rolls, 1)
+		assert.Equal(t, []bool{true}, roll.Decisions)
+		assert.Equal(t, roll.Rolls[0], roll.TurnScore)
+		total += roll.TurnScore
+	}
+	assert.Len(t, sticker.results, 20)
+	assert.Equal(t, total, sticker.GetTrueScore())
+
+	// rolling forever always ends in a bust
+	for _, roll := range gambler.results {
+		assert.True(t, roll.Bust)
+		assert.Equal(t, 0, roll.TurnScore)
+	}
+	assert.Len(t, gambler.results, 20)
+	assert.Equal(t, 10, gambler.GetTrueScore())
+}
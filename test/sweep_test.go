@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/sweep"
+)
+
+// Test the Gini coefficient on equal, fully unequal and negative scores
+func TestGini(t *testing.T) {
+	cases := []struct {
+		scores   []float64
+		expected float64
+	}{
+		{[]float64{5, 5, 5, 5}, 0},
+		{[]float64{0, 0, 0, 8}, 0.75},
+		{[]float64{-3, 0, 0, 8}, 0.75},
+		{[]float64{1, 2, 3, 4}, 0.25},
+		{[]float64{}, 0},
+	}
+	for _, c := range cases {
+		if gini := sweep.Gini(c.scores); math.Abs(gini-c.expected) > 1e-9 {
+			t.Errorf("Gini(%v): expected %v, got %v", c.scores, c.expected, gini)
+		}
+	}
+}
+
+// Test that the grid is expanded into every combination and that cheater ratios split the Team1 agents
+func TestSweepCells(t *testing.T) {
+	base := config.DefaultConfig()
+	grid := sweep.Grid{
+		AgentsPerTeam:  []map[string]int{{"Team1": 10, "Team4": 2}},
+		ThresholdTurns: []int{2, 4},
+		ForcedAoAs:     []int{0, 1, 4},
+		CheaterRatios:  []float64{0.5},
+	}
+	cells := grid.Cells(base)
+	if len(cells) != 6 {
+		t.Fatalf("expected 6 cells, got %d", len(cells))
+	}
+
+	scenario := cells[0].Scenario(base, 7)
+	if err := scenario.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scenario.Server.Seed != 7 || scenario.Server.TeamFormingDelay != 0 {
+		t.Errorf("expected seed 7 and no team forming delay, got %v and %v", scenario.Server.Seed, scenario.Server.TeamFormingDelay)
+	}
+	if scenario.Server.MajorityVoteThreshold != base.Server.MajorityVoteThreshold {
+		t.Errorf("expected the base majority vote threshold, got %v", scenario.Server.MajorityVoteThreshold)
+	}
+	types := make(map[string]int)
+	for _, entry := range scenario.Population {
+		types[entry.Agent+entry.AgentType] += entry.Count
+		// the entries keep the settings of the base population
+		if entry.VerboseLevel != 10 {
+			t.Errorf("expected %s agents to keep the base verbose level, got %d", entry.Agent, entry.VerboseLevel)
+		}
+	}
+	if types["Team1Honest"] != 5 || types["Team1CheatShortTerm"] != 3 || types["Team1CheatLongTerm"] != 2 || types["Team4"] != 2 {
+		t.Errorf("unexpected population: %v", types)
+	}
+	if scenario.NumAgents() != 12 || base.NumAgents() != 30 {
+		t.Errorf("expected 12 agents in the cell and an unchanged base, got %d and %d", scenario.NumAgents(), base.NumAgents())
+	}
+}
+
+// Test that a small sweep runs and produces one summary row per cell
+func TestSweepRun(t *testing.T) {
+	base := config.DefaultConfig()
+	base.Server.Turns = 5
+	base.Server.MaxDuration = time.Millisecond
+
+	sweepConfig := &sweep.SweepConfig{
+		Seeds:   2,
+		Workers: 2,
+		Grid: sweep.Grid{
+			AgentsPerTeam: []map[string]int{{"Team1": 3, "Base": 3}},
+			ForcedAoAs:    []int{1, 4},
+		},
+	}
+	logOutput := log.Writer()
+	results, err := sweep.RunFrom(sweepConfig, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if log.Writer() != logOutput {
+		t.Errorf("expected the sweep to restore the log output")
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Runs != 2 {
+			t.Errorf("expected 2 runs per cell, got %d", result.Runs)
+		}
+		if result.SurvivalRate < 0 || result.SurvivalRate > 1 {
+			t.Errorf("survival rate out of range: %v", result.SurvivalRate)
+		}
+		if result.AoAAdoption[result.Cell.ForcedAoA] != 1 {
+			t.Errorf("expected every team to adopt the forced AoA %d, got %v", result.Cell.ForcedAoA, result.AoAAdoption)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := sweep.WriteSummary(buffer, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buffer.String()), "\n"); len(lines) != 3 {
+		t.Errorf("expected a header and 2 rows, got %d lines", len(lines))
+	}
+}
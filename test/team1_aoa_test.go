@@ -22,7 +22,7 @@ func TestDefaultVoteIsMedian(t *testing.T) {
 	// Test all agents as a potential 'chair'. This might seem superfluous but
 	// will make more sense as teams start adding their own strategies etc.
 	for _, agent := range serv.GetAgentMap() {
-		result := agent.Team1_AgreeRankBoundaries()
+		result := agent.(common.IRankBoundaryAgent).Team1_AgreeRankBoundaries()
 		assert.Equal(t, [5]int{10, 20, 30, 40, 50}, result)
 	}
 }
@@ -43,7 +43,7 @@ func TestCondorcetWinner(t *testing.T) {
 	// Force AoA to team 1
 	teamID := serv.CreateAndInitTeamWithAgents(agentIDs)
 	team := serv.GetTeamFromTeamID(teamID)
-	team.TeamAoA = common.CreateTeam1AoA(team)
+	team.TeamAoA = common.CreateTeam1AoA(team, serv.NewRand())
 
 	/* Mock function to overwrite the voting of an agent. This particular
 	 * function simulates a random vote. Note that this rarely produces a
@@ -66,7 +66,7 @@ func TestCondorcetWinner(t *testing.T) {
 	defer monkey.UnpatchAll()
 
 	testAgents := team.TeamAoA.(*common.Team1AoA).SelectNChairs(agentIDs, 2)
-	res1 := serv.GetAgentMap()[testAgents[0]].Team1_AgreeRankBoundaries()
-	res2 := serv.GetAgentMap()[testAgents[1]].Team1_AgreeRankBoundaries()
+	res1 := serv.GetAgentMap()[testAgents[0]].(common.IRankBoundaryAgent).Team1_AgreeRankBoundaries()
+	res2 := serv.GetAgentMap()[testAgents[1]].(common.IRankBoundaryAgent).Team1_AgreeRankBoundaries()
 	assert.Equal(t, res1, res2)
 }
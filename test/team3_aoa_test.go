@@ -0,0 +1,191 @@
+package main
+
+/*
+* Code to test the AoA functionality for Team 3
+ */
+
+import (
+	"testing"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	envServer "github.com/ADimoska/SOMASExtended/server"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTeam3Team() (*envServer.EnvironmentServer, *common.Team, []uuid.UUID) {
+	serv, agentIDs := CreateTestServer()
+	common.SortUUIDs(agentIDs)
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(agentIDs))
+	team.TeamAoA = common.CreateTeam3AoA(team)
+	team.TeamAoAID = 3
+	return serv, team, agentIDs
+}
+
+func TestTeam3ProgressiveContribution(t *testing.T) {
+	_, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA
+
+	// nothing below the tax-free score, 20% up to 20 and 40% above
+	assert.Equal(t, 0, aoa.GetExpectedContribution(agentIDs[0], 5))
+	assert.Equal(t, 3, aoa.GetExpectedContribution(agentIDs[0], 20))
+	assert.Equal(t, 7, aoa.GetExpectedContribution(agentIDs[0], 30))
+}
+
+func TestTeam3ContributionAudit(t *testing.T) {
+	_, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA
+	honest, liar, freeRider := agentIDs[0], agentIDs[1], agentIDs[2]
+
+	aoa.SetContributionAuditResult(honest, 30, 7, 7)
+	aoa.SetContributionAuditResult(liar, 30, 7, 9)
+	aoa.SetContributionAuditResult(freeRider, 30, 2, 2)
+
+	assert.False(t, aoa.GetContributionAuditResult(honest))
+	assert.True(t, aoa.GetContributionAuditResult(liar))
+	assert.True(t, aoa.GetContributionAuditResult(freeRider))
+
+	// an audit clears the infractions it found
+	assert.False(t, aoa.GetContributionAuditResult(liar))
+}
+
+func TestTeam3WithdrawalAudit(t *testing.T) {
+	_, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA
+	share := aoa.GetExpectedWithdrawal(agentIDs[0], 0, 200)
+	assert.Equal(t, 200/len(team.Agents), share)
+
+	for _, agentID := range agentIDs[:2] {
+		aoa.SetContributionAuditResult(agentID, 0, 0, 0)
+	}
+	aoa.SetWithdrawalAuditResult(agentIDs[0], 0, share, share, 200)
+	aoa.SetWithdrawalAuditResult(agentIDs[1], 0, share+1, share+1, 200)
+
+	assert.False(t, aoa.GetWithdrawalAuditResult(agentIDs[0]))
+	assert.True(t, aoa.GetWithdrawalAuditResult(agentIDs[1]))
+}
+
+// An agent audited for its contribution can still be caught over-withdrawing in the same turn
+func TestTeam3WithdrawalAuditAfterContributionAudit(t *testing.T) {
+	_, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA
+	share := aoa.GetExpectedWithdrawal(agentIDs[0], 0, 200)
+
+	aoa.SetContributionAuditResult(agentIDs[0], 30, 7, 7)
+	assert.False(t, aoa.GetContributionAuditResult(agentIDs[0]))
+	aoa.SetWithdrawalAuditResult(agentIDs[0], 0, share+1, share+1, 200)
+	assert.True(t, aoa.GetWithdrawalAuditResult(agentIDs[0]))
+}
+
+// Every member is entitled to the same share of the pool, however much was withdrawn before it
+func TestTeam3EqualShares(t *testing.T) {
+	serv, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA.(*common.Team3AoA)
+	team.SetCommonPool(200)
+	share := 200 / len(agentIDs)
+
+	state := &common.TurnState{Team: team}
+	aoa.BeforeTurnPhase(common.WithdrawPhase, serv, state)
+	pool := team.GetCommonPool()
+	for _, agentID := range agentIDs {
+		aoa.SetContributionAuditResult(agentID, 0, 0, 0)
+		assert.Equal(t, share, aoa.GetExpectedWithdrawal(agentID, 0, pool))
+		aoa.SetWithdrawalAuditResult(agentID, 0, share, share, pool)
+		pool -= share
+	}
+	aoa.AfterTurnPhase(common.WithdrawPhase, serv, state)
+
+	for _, agentID := range agentIDs {
+		assert.False(t, aoa.GetWithdrawalAuditResult(agentID))
+	}
+	// outside the withdrawal phase the share follows the pool
+	assert.Equal(t, 0, aoa.GetExpectedWithdrawal(agentIDs[0], 0, pool))
+}
+
+func TestTeam3WithdrawalOrder(t *testing.T) {
+	_, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA
+
+	aoa.SetContributionAuditResult(agentIDs[3], 30, 10, 10)
+	aoa.SetContributionAuditResult(agentIDs[5], 30, 20, 20)
+
+	order := aoa.GetWithdrawalOrder(agentIDs)
+	assert.Len(t, order, len(agentIDs))
+	assert.Equal(t, agentIDs[5], order[0])
+	assert.Equal(t, agentIDs[3], order[1])
+	// the agents that did not contribute keep a fixed order
+	assert.Equal(t, agentIDs[0], order[2])
+}
+
+func TestTeam3VoteNeedsMajority(t *testing.T) {
+	_, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA
+	suspect := agentIDs[0]
+
+	votes := []common.Vote{}
+	for i, voterID := range agentIDs {
+		isVote := 0
+		if i < len(agentIDs)/2 {
+			isVote = 1
+		}
+		votes = append(votes, common.CreateVote(isVote, voterID, suspect))
+	}
+	assert.Equal(t, uuid.Nil, aoa.GetVoteResult(votes))
+
+	votes[len(agentIDs)/2].IsVote = 1
+	assert.Equal(t, suspect, aoa.GetVoteResult(votes))
+}
+
+// Punish the agent as the punishment phase does after it was caught by an audit
+func punishTeam3Agent(serv common.ITurnServer, team *common.Team, agentID uuid.UUID) {
+	punish, _ := team.TeamAoA.(common.ITurnPhaseOverrides).OverrideTurnPhase(common.PunishmentPhase)
+	punish(serv, &common.TurnState{Team: team, AuditedAgent: agentID, AuditResult: true})
+}
+
+func TestTeam3EscalatingPunishment(t *testing.T) {
+	serv, team, agentIDs := createTeam3Team()
+	aoa := team.TeamAoA.(*common.Team3AoA)
+
+	// the fine does not count an offence by itself
+	assert.Equal(t, 0, aoa.GetPunishment(100, agentIDs[0]))
+	punishTeam3Agent(serv, team, agentIDs[0])
+	assert.Equal(t, 20, aoa.GetPunishment(100, agentIDs[0]))
+	assert.Equal(t, 20, aoa.GetPunishment(100, agentIDs[0]))
+	punishTeam3Agent(serv, team, agentIDs[0])
+	assert.Equal(t, 40, aoa.GetPunishment(100, agentIDs[0]))
+	assert.Equal(t, 0, aoa.GetPunishment(100, agentIDs[1]))
+	for i := 0; i < 6; i++ {
+		punishTeam3Agent(serv, team, agentIDs[0])
+	}
+	assert.Equal(t, 100, aoa.GetPunishment(100, agentIDs[0]))
+	assert.Equal(t, 8, aoa.GetOffences(agentIDs[0]))
+
+	// agents cleared by the audit are not punished
+	punish, _ := aoa.OverrideTurnPhase(common.PunishmentPhase)
+	punish(serv, &common.TurnState{Team: team, AuditedAgent: agentIDs[1]})
+	assert.Equal(t, 0, aoa.GetOffences(agentIDs[1]))
+}
+
+// Test that audits are paid for from the common pool, and skipped when it can not pay
+func TestTeam3PaidAudit(t *testing.T) {
+	serv, team, agentIDs := createTeam3Team()
+	audit, ok := team.TeamAoA.(common.ITurnPhaseOverrides).OverrideTurnPhase(common.ContributionAuditPhase)
+	assert.True(t, ok)
+
+	votes := []common.Vote{}
+	for _, voterID := range agentIDs {
+		votes = append(votes, common.CreateVote(1, voterID, agentIDs[0]))
+	}
+	team.SetCommonPool(50)
+	state := &common.TurnState{Team: team, AuditPhase: common.ContributionAuditPhase, ContributionAuditVotes: votes}
+	audit(serv, state)
+	assert.Equal(t, agentIDs[0], state.AuditedAgent)
+	assert.Equal(t, 5, state.AuditCost)
+	assert.Equal(t, 45, team.GetCommonPool())
+
+	team.SetCommonPool(0)
+	state = &common.TurnState{Team: team, AuditPhase: common.ContributionAuditPhase, ContributionAuditVotes: votes}
+	audit(serv, state)
+	assert.Equal(t, uuid.Nil, state.AuditedAgent)
+	assert.Equal(t, 0, state.AuditCost)
+}
@@ -0,0 +1,93 @@
+package main
+
+/*
+* Code to test that the team records describe the team's AoA, members and flows
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamRecordFlows(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            12,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             1,
+			ForcedAoA:        4,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 6},
+			{Agent: "Team1", Count: 6},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+
+	previousPool := map[uuid.UUID]int{}
+	for _, turn := range serv.DataRecorder.TurnRecords {
+		for _, team := range turn.TeamRecords {
+			assert.Equal(t, 4, team.TeamAoAID)
+			for _, agentID := range team.Agents {
+				assert.NotContains(t, team.AgentsDead, agentID)
+			}
+			for _, agentID := range team.Agents {
+				assert.Contains(t, team.Ranks, agentID)
+			}
+
+			// everything that enters or leaves the pool is accounted for
+			if pool, ok := previousPool[team.TeamID]; ok {
+				assert.Equal(t, pool+team.TotalContributed-team.TotalWithdrawn-team.AuditCostPaid+team.PunishmentsCollected, team.TeamCommonPool)
+			}
+			previousPool[team.TeamID] = team.TeamCommonPool
+		}
+	}
+
+	// agents that die are listed as dead members of their team for the rest of the iteration
+	killed := 0
+	for _, event := range serv.DataRecorder.Events {
+		if event.Type != gameRecorder.AgentKilledEvent || event.TeamID == uuid.Nil {
+			continue
+		}
+		killed++
+		for _, turn := range serv.DataRecorder.TurnRecords {
+			if turn.CommonRecord.IterationNumber != event.IterationNumber || turn.CommonRecord.TurnNumber != event.TurnNumber {
+				continue
+			}
+			index := slices.IndexFunc(turn.TeamRecords, func(team gameRecorder.TeamRecord) bool { return team.TeamID == event.TeamID })
+			assert.Contains(t, turn.TeamRecords[index].AgentsDead, event.AgentID)
+		}
+	}
+	assert.Greater(t, killed, 0)
+}
+
+func TestTeamRecordLeader(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(agentIDs))
+	common.AdoptAoA(2, team, serv)
+
+	record := team.RecordTeamStatus()
+	assert.Equal(t, 2, record.TeamAoAID)
+	assert.Equal(t, team.TeamAoA.(common.ILeaderElectionAoA).GetLeader(), record.Leader)
+	assert.NotEqual(t, uuid.Nil, record.Leader)
+	assert.Empty(t, record.Ranks)
+	assert.ElementsMatch(t, agentIDs, record.Agents)
+}
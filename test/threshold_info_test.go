@@ -0,0 +1,121 @@
+package main
+
+/*
+* Code to test what agents are told about the threshold
+ */
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/stretchr/testify/assert"
+)
+
+// An agent that keeps every threshold notification
+type thresholdListener struct {
+	*agents.ExtendedAgent
+	notifications []common.ThresholdNotificationMessage
+}
+
+// The listener is never below the threshold, whether or not it joins a team, so it is
+// told about every threshold
+func (tl *thresholdListener) GetTrueScore() int {
+	return 1000
+}
+
+func (tl *thresholdListener) HandleThresholdNotificationMessage(msg *common.ThresholdNotificationMessage) {
+	tl.notifications = append(tl.notifications, *msg)
+}
+
+// Run a short game with the info mode and return what the listener was told, and the
+// thresholds applied, in order
+func runThresholdInfoScenario(t *testing.T, info string, infoRange int) ([]common.ThresholdNotificationMessage, []int) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       2,
+			Turns:            10,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             9,
+			Threshold:        config.ThresholdConfig{Info: info, InfoRange: infoRange},
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	listener := &thresholdListener{ExtendedAgent: agents.GetBaseAgents(serv, agents.AgentConfig{})}
+	serv.AddAgent(listener)
+	serv.Start()
+
+	// the threshold recorded in a turn is the one applied next, the record of the turn
+	// in which it is applied already holds the following one
+	thresholds := []int{}
+	var previous gameRecorder.CommonRecord
+	for _, turn := range serv.DataRecorder.TurnRecords {
+		record := turn.CommonRecord
+		if record.ThresholdAppliedInTurn {
+			thresholds = append(thresholds, previous.Threshold)
+		}
+		assert.Equal(t, info, record.ThresholdInfo)
+		previous = record
+	}
+	return listener.notifications, thresholds
+}
+
+func TestThresholdInfoNone(t *testing.T) {
+	notifications, _ := runThresholdInfoScenario(t, "none", 0)
+	assert.Empty(t, notifications)
+}
+
+func TestThresholdInfoExact(t *testing.T) {
+	notifications, thresholds := runThresholdInfoScenario(t, "exact", 0)
+	announced := []int{}
+	for _, msg := range notifications {
+		assert.Equal(t, common.ThresholdInfoExact, msg.Mode)
+		assert.False(t, msg.Applied)
+		assert.Equal(t, msg.Min, msg.Max)
+		assert.Equal(t, 0, msg.Turn%3)
+		// the threshold announced for turn 12 is never applied in a 10 turn iteration
+		if msg.Turn <= 10 {
+			announced = append(announced, msg.Min)
+		}
+	}
+	// every threshold is announced before it is applied
+	assert.NotEmpty(t, thresholds)
+	assert.Equal(t, thresholds, announced)
+}
+
+func TestThresholdInfoRange(t *testing.T) {
+	notifications, _ := runThresholdInfoScenario(t, "range", 6)
+	assert.NotEmpty(t, notifications)
+	for _, msg := range notifications {
+		assert.Equal(t, common.ThresholdInfoRange, msg.Mode)
+		assert.Equal(t, 6, msg.Max-msg.Min)
+		assert.GreaterOrEqual(t, msg.Min, 0)
+	}
+}
+
+func TestThresholdInfoAfterApplied(t *testing.T) {
+	notifications, thresholds := runThresholdInfoScenario(t, "afterApplied", 0)
+	assert.NotEmpty(t, notifications)
+	for _, msg := range notifications {
+		assert.True(t, msg.Applied)
+		assert.Equal(t, msg.Min, msg.Max)
+		assert.Equal(t, 0, msg.Turn%3)
+	}
+	assert.Len(t, notifications, len(thresholds))
+}
@@ -0,0 +1,114 @@
+package main
+
+/*
+* Code to test the threshold policies
+ */
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ADimoska/SOMASExtended/common"
+	"github.com/ADimoska/SOMASExtended/config"
+	"github.com/ADimoska/SOMASExtended/gameRecorder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdPolicies(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	assert.Equal(t, 12, common.FixedThreshold{Value: 12}.NextThreshold(common.ThresholdState{Turn: 30}, rng))
+
+	linear := common.LinearThreshold{Base: 5, Growth: 0.5, Spread: 0}
+	assert.Equal(t, 5, linear.NextThreshold(common.ThresholdState{Turn: 0}, rng))
+	assert.Equal(t, 10, linear.NextThreshold(common.ThresholdState{Turn: 10}, rng))
+	for i := 0; i < 100; i++ {
+		threshold := common.DefaultThresholdPolicy().NextThreshold(common.ThresholdState{Turn: 6}, rng)
+		assert.GreaterOrEqual(t, threshold, 6)
+		assert.Less(t, threshold, 16)
+	}
+
+	walk := common.RandomWalkThreshold{Start: 8, Step: 3}
+	assert.Equal(t, 8, walk.NextThreshold(common.ThresholdState{}, rng))
+	for i := 0; i < 100; i++ {
+		assert.InDelta(t, 8, walk.NextThreshold(common.ThresholdState{Previous: 8, HasPrevious: true}, rng), 3)
+		// never below 0
+		assert.GreaterOrEqual(t, walk.NextThreshold(common.ThresholdState{Previous: 1, HasPrevious: true}, rng), 0)
+	}
+
+	median := common.MedianThreshold{Fraction: 0.5}
+	assert.Equal(t, 10, median.NextThreshold(common.ThresholdState{Scores: []int{40, 2, 20}}, rng))
+	assert.Equal(t, 15, median.NextThreshold(common.ThresholdState{Scores: []int{40, 2, 20, 50}}, rng))
+	assert.Equal(t, 0, median.NextThreshold(common.ThresholdState{}, rng))
+}
+
+func runThresholdScenario(t *testing.T, threshold config.ThresholdConfig) []gameRecorder.TurnRecord {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cfg := &config.SimulationConfig{
+		Server: config.ServerConfig{
+			Iterations:       1,
+			Turns:            10,
+			MaxDuration:      time.Millisecond,
+			MessageBandwidth: 10,
+			ThresholdTurns:   3,
+			Seed:             8,
+			Threshold:        threshold,
+		},
+		Population: []config.PopulationEntry{
+			{Agent: "Team4", Count: 4},
+			{Agent: "Team1", Count: 4},
+		},
+	}
+	serv, err := config.BuildSimulation(cfg)
+	assert.NoError(t, err)
+	serv.Start()
+	return serv.DataRecorder.TurnRecords
+}
+
+func TestFixedThresholdRecorded(t *testing.T) {
+	turns := runThresholdScenario(t, config.ThresholdConfig{Policy: "fixed", Value: 7})
+	assert.NotEmpty(t, turns)
+	for _, turn := range turns {
+		if turn.TurnNumber == 0 {
+			continue
+		}
+		assert.Equal(t, 7, turn.CommonRecord.Threshold)
+		assert.Equal(t, "fixed", turn.CommonRecord.ThresholdPolicy)
+		assert.Equal(t, map[string]float64{"value": 7}, turn.CommonRecord.ThresholdParameters)
+		assert.Equal(t, 3, turn.CommonRecord.ThresholdTurns)
+		assert.False(t, turn.CommonRecord.ThresholdHidden)
+	}
+}
+
+func TestHiddenThresholdChosenWhenApplied(t *testing.T) {
+	turns := runThresholdScenario(t, config.ThresholdConfig{Policy: "randomWalk", Value: 4, Step: 2, Hidden: true})
+	applied := 0
+	for _, turn := range turns {
+		if turn.TurnNumber == 0 {
+			continue
+		}
+		assert.True(t, turn.CommonRecord.ThresholdHidden)
+		if turn.CommonRecord.ThresholdAppliedInTurn {
+			// the first hidden threshold is the start of the walk
+			if applied == 0 {
+				assert.Equal(t, 4, turn.CommonRecord.Threshold)
+			}
+			applied++
+		} else if applied == 0 {
+			// nothing has been chosen before the first application
+			assert.Equal(t, 0, turn.CommonRecord.Threshold)
+		}
+	}
+	assert.Greater(t, applied, 1)
+
+	_, err := config.ThresholdConfig{Policy: "exponential"}.NewThresholdPolicy()
+	assert.Error(t, err)
+	_, err = config.ThresholdConfig{Policy: "median", Fraction: -1}.NewThresholdPolicy()
+	assert.Error(t, err)
+}
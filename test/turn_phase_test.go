@@ -0,0 +1,247 @@
+package main
+
+/*
+* Code to test the turn phases run by the server and how AoAs change them
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ADimoska/SOMASExtended/agents"
+	"github.com/ADimoska/SOMASExtended/common"
+	envServer "github.com/ADimoska/SOMASExtended/server"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// An AoA that counts how often the votes on an audit are counted
+type voteCountingAoA struct {
+	common.IArticlesOfAssociation
+	voteResults int
+}
+
+func (a *voteCountingAoA) GetVoteResult(votes []common.Vote) uuid.UUID {
+	a.voteResults++
+	return a.IArticlesOfAssociation.GetVoteResult(votes)
+}
+
+// A paid audit counts the votes once, and the audit it pays for audits the agent it paid for
+func TestPaidAuditCountsVotesOnce(t *testing.T) {
+	serv, agentIDs := CreateTestServer()
+	common.SortUUIDs(agentIDs)
+	team := serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(agentIDs[:3]))
+	aoa := &voteCountingAoA{IArticlesOfAssociation: common.CreateTeam5AoA(serv.NewRand())}
+	team.TeamAoA = aoa
+	team.SetCommonPool(100)
+
+	suspect := agentIDs[2]
+	state := &common.TurnState{Team: team, AuditPhase: common.ContributionAuditPhase}
+	for _, agentID := range team.Agents {
+		state.ContributionAuditVotes = append(state.ContributionAuditVotes, common.CreateVote(1, agentID, suspect))
+	}
+	common.RunPaidAudit(serv, state)
+
+	assert.Equal(t, 1, aoa.voteResults)
+	assert.Equal(t, suspect, state.AuditedAgent)
+	assert.Equal(t, 5, state.AuditCost)
+	assert.Equal(t, 95, team.GetCommonPool())
+}
+
+// An AoA that replaces the roll phase, and logs every phase it hooks into with a copy of the
+// state it was given
+type hookedAoA struct {
+	common.IArticlesOfAssociation
+	log             []string
+	states          map[string]common.TurnState
+	stateRefs       []*common.TurnState
+	scoresAfterRoll []int
+}
+
+func (a *hookedAoA) OverrideTurnPhase(phase common.TurnPhase) (common.TurnPhaseFunc, bool) {
+	if phase != common.RollPhase {
+		return nil, false
+	}
+	return func(server common.ITurnServer, state *common.TurnState) {
+		a.log = append(a.log, "override "+phase.String())
+	}, true
+}
+
+func (a *hookedAoA) BeforeTurnPhase(phase common.TurnPhase, server common.ITurnServer, state *common.TurnState) {
+	a.hook("before "+phase.String(), state)
+}
+
+func (a *hookedAoA) AfterTurnPhase(phase common.TurnPhase, server common.ITurnServer, state *common.TurnState) {
+	a.hook("after "+phase.String(), state)
+	if phase == common.RollPhase {
+		for _, agentID := range state.Team.Agents {
+			a.scoresAfterRoll = append(a.scoresAfterRoll, server.GetAgentMap()[agentID].GetTrueScore())
+		}
+	}
+}
+
+func (a *hookedAoA) hook(entry string, state *common.TurnState) {
+	a.log = append(a.log, entry)
+	a.states[entry] = *state
+	a.stateRefs = append(a.stateRefs, state)
+}
+
+// An agent with a fixed strategy, so that the effect of a turn can be worked out by hand.
+// It votes to audit the suspect, and logs when it votes on a punishment and hears an audit result.
+type scriptedAgent struct {
+	*agents.ExtendedAgent
+	contribution, statedContribution int
+	withdrawal, statedWithdrawal     int
+	suspect                          uuid.UUID
+	punishment                       int
+	log                              *[]string
+}
+
+func (sa *scriptedAgent) GetActualContribution(instance common.IExtendedAgent) int {
+	return sa.contribution
+}
+
+func (sa *scriptedAgent) GetStatedContribution(instance common.IExtendedAgent) int {
+	return sa.statedContribution
+}
+
+func (sa *scriptedAgent) GetActualWithdrawal(instance common.IExtendedAgent) int {
+	return sa.withdrawal
+}
+
+func (sa *scriptedAgent) GetStatedWithdrawal(instance common.IExtendedAgent) int {
+	return sa.statedWithdrawal
+}
+
+func (sa *scriptedAgent) GetContributionAuditVote() common.Vote {
+	return common.CreateVote(1, sa.GetID(), sa.suspect)
+}
+
+func (sa *scriptedAgent) GetWithdrawalAuditVote() common.Vote {
+	return common.CreateVote(1, sa.GetID(), sa.suspect)
+}
+
+func (sa *scriptedAgent) Team4_GetPunishmentVoteMap() map[int]int {
+	*sa.log = append(*sa.log, "punishment vote")
+	return map[int]int{sa.punishment: 1}
+}
+
+func (sa *scriptedAgent) SetAgentWithdrawalAuditResult(agentID uuid.UUID, result bool) {
+	*sa.log = append(*sa.log, fmt.Sprintf("withdrawal audit of %v: %v", agentID, result))
+	sa.ExtendedAgent.SetAgentWithdrawalAuditResult(agentID, result)
+}
+
+// Create a team of two honest agents and a cheat, each with a score of 100, who all suspect the
+// cheat. Rolls yield nothing, so that scores only change through the common pool.
+func createScriptedTeam(serv *envServer.EnvironmentServer, honest scriptedAgent, cheat scriptedAgent) (*common.Team, []*scriptedAgent) {
+	droughtGame, _ := common.NewDroughtGame(3, 6, 1)
+	serv.SetResourceGame(droughtGame)
+	log := []string{}
+	members := []*scriptedAgent{}
+	memberIDs := []uuid.UUID{}
+	for _, script := range []scriptedAgent{honest, honest, cheat} {
+		member := script
+		member.ExtendedAgent = agents.GetBaseAgents(serv, agents.AgentConfig{})
+		member.log = &log
+		member.SetTrueScore(100)
+		serv.AddAgent(&member)
+		members = append(members, &member)
+		memberIDs = append(memberIDs, member.GetID())
+	}
+	for _, member := range members {
+		member.suspect = memberIDs[2]
+	}
+	return serv.GetTeamFromTeamID(serv.CreateAndInitTeamWithAgents(memberIDs)), members
+}
+
+// Team4 confesses and votes on the punishment of the agent chosen for a withdrawal audit before the
+// team hears the result, and the fine goes from the agent to the common pool
+func TestTeam4PunishmentVoteBeforeAuditResult(t *testing.T) {
+	serv, _ := CreateTestServer()
+	team, members := createScriptedTeam(serv,
+		scriptedAgent{contribution: 10, statedContribution: 10, withdrawal: 5, statedWithdrawal: 5, punishment: 2},
+		scriptedAgent{contribution: 2, statedContribution: 10, withdrawal: 10, statedWithdrawal: 2, punishment: 2})
+	team.TeamAoA = common.CreateTeam4AoA(team)
+	team.TeamAoAID = 4
+	cheat := members[2].GetID()
+
+	serv.RunTeamTurn(team)
+
+	// every member votes on the punishment before any of them hears the result
+	assert.Equal(t, []string{"punishment vote", "punishment vote", "punishment vote"}, (*members[0].log)[:3])
+	assert.Len(t, *members[0].log, 6)
+	for _, entry := range (*members[0].log)[3:] {
+		assert.Equal(t, fmt.Sprintf("withdrawal audit of %v: true", cheat), entry)
+	}
+	// the cheat is fined 25% of its score of 100 - 2 + 10
+	assert.Equal(t, 95, members[0].GetTrueScore())
+	assert.Equal(t, 95, members[1].GetTrueScore())
+	assert.Equal(t, 108-27, members[2].GetTrueScore())
+	assert.Equal(t, 22-20+27, team.GetCommonPool())
+}
+
+// An overridden phase replaces the server's, and the hooks run around every phase in turn order
+func TestTurnPhaseOverridesAndHooks(t *testing.T) {
+	serv, _ := CreateTestServer()
+	team, members := createScriptedTeam(serv,
+		scriptedAgent{contribution: 10, statedContribution: 10, withdrawal: 5, statedWithdrawal: 5},
+		scriptedAgent{contribution: 2, statedContribution: 10, withdrawal: 10, statedWithdrawal: 2})
+	// rolls would raise every score if the roll phase were not replaced
+	serv.SetResourceGame(common.DefaultResourceGame())
+	aoa := &hookedAoA{IArticlesOfAssociation: common.CreateTeam5AoA(serv.NewRand()), states: make(map[string]common.TurnState)}
+	team.TeamAoA = aoa
+	cheat := members[2].GetID()
+
+	serv.RunTeamTurn(team)
+
+	expected := []string{}
+	for _, phase := range common.TurnPhases {
+		expected = append(expected, "before "+phase.String())
+		if phase == common.RollPhase {
+			expected = append(expected, "override "+phase.String())
+		}
+		expected = append(expected, "after "+phase.String())
+	}
+	assert.Equal(t, expected, aoa.log)
+	assert.Equal(t, []int{100, 100, 100}, aoa.scoresAfterRoll)
+
+	// every hook is given the team's state for this turn
+	for _, state := range aoa.stateRefs {
+		assert.Same(t, aoa.stateRefs[0], state)
+	}
+	assert.Same(t, team, aoa.stateRefs[0].Team)
+	// the after hooks see what the phase did, the before hooks do not
+	assert.Equal(t, 0, aoa.states["before Contribute"].Contributed)
+	assert.Equal(t, 22, aoa.states["after Contribute"].Contributed)
+	for _, phase := range []common.TurnPhase{common.ContributionAuditPhase, common.WithdrawalAuditPhase} {
+		assert.Equal(t, phase, aoa.states["before "+phase.String()].AuditPhase)
+		assert.Equal(t, uuid.Nil, aoa.states["before "+phase.String()].AuditedAgent)
+		assert.Equal(t, cheat, aoa.states["after "+phase.String()].AuditedAgent)
+	}
+	assert.Equal(t, 0, aoa.states["before Withdraw"].Withdrawn)
+	assert.Equal(t, 20, aoa.states["after Withdraw"].Withdrawn)
+}
+
+// Team5 holds agents to the expected contribution, pays for audits from the common pool, and
+// neither punishes agents nor has them state their withdrawals
+func TestTeam5TurnPhases(t *testing.T) {
+	serv, _ := CreateTestServer()
+	team, members := createScriptedTeam(serv,
+		scriptedAgent{contribution: 75, withdrawal: 20, statedWithdrawal: 20},
+		scriptedAgent{contribution: 10, withdrawal: 50, statedWithdrawal: 10})
+	team.TeamAoA = common.CreateTeam5AoA(serv.NewRand())
+	team.TeamAoAID = 5
+	cheat := members[2].GetID()
+
+	serv.RunTeamTurn(team)
+
+	// the contribution audit costs 5% of 160 and the withdrawal audit 5% of 62
+	assert.Equal(t, 45, members[0].GetTrueScore())
+	assert.Equal(t, 45, members[1].GetTrueScore())
+	assert.Equal(t, 140, members[2].GetTrueScore())
+	assert.Equal(t, 160-8-90-3, team.GetCommonPool())
+	assert.Len(t, *members[0].log, 3)
+	for _, entry := range *members[0].log {
+		assert.Equal(t, fmt.Sprintf("withdrawal audit of %v: true", cheat), entry)
+	}
+}